@@ -0,0 +1,337 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	tree "github.com/danielsussa/tmp_tree"
+	"github.com/danielsussa/tmp_tree/explorer"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"time"
+)
+
+// gameFlags are shared by the commands running a game against a store
+type gameFlags struct {
+	game    *string
+	backend *string
+	path    *string
+	seed    *int64
+}
+
+func newGameFlags(fs *flag.FlagSet) *gameFlags {
+	f := &gameFlags{
+		game: fs.String("game", "tictactoe", "game to play, one of the registered games"),
+		seed: fs.Int64("seed", 0, "seed of the search and of the side effects, 0 picks one from the clock"),
+	}
+	f.backend, f.path = storeFlags(fs, "", "tree")
+	return f
+}
+
+// open the store, the tree using it and the game
+func (f *gameFlags) open() (store, *tree.StateTree, *rand.Rand, tree.Game, error) {
+	game, err := lookupGame(*f.game)
+	if err != nil {
+		return nil, nil, nil, game, err
+	}
+	db, err := openStore(*f.backend, *f.path)
+	if err != nil {
+		return nil, nil, nil, game, err
+	}
+	seed := *f.seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return db, tree.New().SetDB(db).Seed(seed), rand.New(rand.NewSource(seed)), game, nil
+}
+
+type searchFlags struct {
+	iterations *int
+	timeout    *time.Duration
+	solver     *bool
+	reuse      *bool
+}
+
+func newSearchFlags(fs *flag.FlagSet, iterations int) *searchFlags {
+	return &searchFlags{
+		iterations: fs.Int("iterations", iterations, "playouts of the search before every move"),
+		timeout:    fs.Duration("timeout", 0, "time limit of the search before every move, 0 for none"),
+		solver:     fs.Bool("solver", false, "prove wins and losses, see StateTreeConfig.Solver"),
+		reuse:      fs.Bool("reuse", true, "keep the tree in memory between moves"),
+	}
+}
+
+func (f *searchFlags) config() tree.StateTreeConfig {
+	config := tree.StateTreeConfig{
+		MaxIterations: *f.iterations,
+		Solver:        *f.solver,
+		ReuseTree:     *f.reuse,
+	}
+	if *f.timeout > 0 {
+		config.MaxTimeout = f.timeout
+	}
+	return config
+}
+
+// playGame search before every move and play the best action until the end
+// of the game, calling onMove after each one
+func playGame(st *tree.StateTree, rng *rand.Rand, state tree.State, config tree.StateTreeConfig, onMove func(action string, state tree.State)) (tree.GameResult, error) {
+	for depth := 1; ; depth++ {
+		result := st.Search(state, config)
+		if result.BestAction == "" {
+			return tree.GameResult{}, fmt.Errorf("state %s was never explored, train it or set -iterations", state.ID())
+		}
+		state.PlayAction(result.BestAction)
+		if random, ok := state.(tree.RandomState); ok {
+			random.PlaySideEffectsWith(tree.SideEffectsRequest{Rand: rng})
+		} else {
+			state.PlaySideEffects()
+		}
+		if onMove != nil {
+			onMove(result.BestAction, state)
+		}
+		if state.TurnResult(tree.TurnRequest{Depth: depth, Rand: rng}).EndGame {
+			return state.GameResult(), nil
+		}
+	}
+}
+
+func runTrain(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("train", flag.ContinueOnError)
+	game := newGameFlags(fs)
+	search := newSearchFlags(fs, 1000)
+	episodes := fs.Int("episodes", 1, "games played, the tree is trained before every move")
+	report := fs.String("report", "terminal", "progress reports: terminal, csv or none")
+	reportEvery := fs.Int("report-every", tree.DefaultReportEvery, "playouts between two progress reports")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	db, st, rng, g, err := game.open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch *report {
+	case "terminal":
+		st.SetReporter(tree.NewTerminalReporter(stdout))
+	case "csv":
+		st.SetReporter(tree.NewCSVReporter(stdout))
+	case "none":
+	default:
+		return fmt.Errorf("unknown report %q", *report)
+	}
+	config := search.config()
+	config.ReportEvery = *reportEvery
+
+	for episode := 1; episode <= *episodes; episode++ {
+		result, err := playGame(st, rng, g.New(), config, nil)
+		if err != nil {
+			return err
+		}
+		if *report != "csv" {
+			fmt.Fprintf(stdout, "episode %d: score %d\n", episode, result.Score)
+		}
+	}
+	return nil
+}
+
+func runPlay(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("play", flag.ContinueOnError)
+	game := newGameFlags(fs)
+	search := newSearchFlags(fs, 0)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *game.backend == "memory" && *search.iterations == 0 && *search.timeout == 0 {
+		return fmt.Errorf("a memory store is empty, set -iterations or -timeout")
+	}
+	db, st, rng, g, err := game.open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	result, err := playGame(st, rng, g.New(), search.config(), func(action string, state tree.State) {
+		fmt.Fprintf(stdout, "%s\n%s\n", action, g.Render(state))
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "score %d\n", result.Score)
+	return nil
+}
+
+func runInspect(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	game := newGameFlags(fs)
+	state := fs.String("state", "", "state to start from, as written by the Encode of the game, the start of a game when empty")
+	stateFile := fs.String("state-file", "", "file holding the state to start from, a trailing newline is ignored")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	db, st, rng, g, err := game.open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	start, err := startState(g, *state, *stateFile)
+	if err != nil {
+		return err
+	}
+	return explorer.Explore(st, start, rng, stdin, stdout)
+}
+
+// startState decode the state given by -state or -state-file, the start of
+// a game when neither is set
+func startState(g tree.Game, state, stateFile string) (tree.State, error) {
+	if state != "" && stateFile != "" {
+		return nil, fmt.Errorf("-state and -state-file are exclusive")
+	}
+	data := []byte(state)
+	if stateFile != "" {
+		var err error
+		if data, err = ioutil.ReadFile(stateFile); err != nil {
+			return nil, err
+		}
+		data = bytes.TrimRight(data, "\r\n")
+	}
+	if len(data) == 0 {
+		return g.New(), nil
+	}
+	s, err := g.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode state: %w", err)
+	}
+	return s, nil
+}
+
+type record struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func runExport(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	backend, path := storeFlags(fs, "", "exported")
+	out := fs.String("o", "", "file written, stdout when empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	db, err := openStore(*backend, *path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	w := stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+	err = db.Each(func(key, val string) error {
+		return encoder.Encode(record{Key: key, Value: val})
+	})
+	if err != nil {
+		return err
+	}
+	return buffered.Flush()
+}
+
+func runImport(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	backend, path := storeFlags(fs, "", "imported")
+	in := fs.String("i", "", "file read, stdin when empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	db, err := openStore(*backend, *path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	r := stdin
+	if *in != "" {
+		f, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	imported := 0
+	for {
+		var rec record
+		if err := decoder.Decode(&rec); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("record %d: %w", imported+1, err)
+		}
+		if err := db.Add(rec.Key, rec.Value); err != nil {
+			return err
+		}
+		imported++
+	}
+	fmt.Fprintf(stdout, "imported %d records\n", imported)
+	return nil
+}
+
+func runMerge(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+	backend, path := storeFlags(fs, "", "merged into")
+	fromBackend, fromPath := storeFlags(fs, "from-", "merged from")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	dst, err := openStore(*backend, *path)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+	src, err := openStore(*fromBackend, *fromPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	merged, err := tree.MergeStore(dst, src)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "merged %d records\n", merged)
+	return nil
+}
+
+func runStats(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	backend, path := storeFlags(fs, "", "summarized")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	db, err := openStore(*backend, *path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	stats, err := tree.ReadStoreStats(db)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "nodes    %d\nactions  %d\nvisits   %d\nproven   %d\nbytes    %d\n",
+		stats.Nodes, stats.Actions, stats.Visits, stats.Proven, stats.Bytes)
+	return nil
+}
@@ -0,0 +1,76 @@
+// Command sktree train, play and inspect the example games and manage the
+// stores their trees are persisted to.
+//
+// Usage:
+//
+//	sktree <command> [flags]
+//
+// Commands:
+//
+//	train    train the tree along self-played games
+//	play     play a game with the best actions of the tree
+//	inspect  explore the tree interactively from a state, the start of a game by default
+//	export   write every record of a store as JSON lines
+//	import   add the records of a JSON lines export to a store
+//	merge    add the statistics of a store to another one
+//	stats    summarize a store
+//
+// Run "sktree <command> -h" for the flags of a command.
+package main
+
+import (
+	"fmt"
+	tree "github.com/danielsussa/tmp_tree"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+type command struct {
+	summary string
+	run     func(args []string, stdin io.Reader, stdout io.Writer) error
+}
+
+var commands = map[string]command{
+	"train":   {"train the tree along self-played games", runTrain},
+	"play":    {"play a game with the best actions of the tree", runPlay},
+	"inspect": {"explore the tree interactively from a state, the start of a game by default", runInspect},
+	"export":  {"write every record of a store as JSON lines", runExport},
+	"import":  {"add the records of a JSON lines export to a store", runImport},
+	"merge":   {"add the statistics of a store to another one", runMerge},
+	"stats":   {"summarize a store", runStats},
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "sktree:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+		usage(stdout)
+		return nil
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		usage(stdout)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	return cmd.run(args[1:], stdin, stdout)
+}
+
+func usage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(w, "usage: sktree <command> [flags]\n\ncommands:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-8s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintf(w, "\ngames: %s\nbackends: %s\n", strings.Join(tree.Games(), ", "), strings.Join(backendNames, ", "))
+}
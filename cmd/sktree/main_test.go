@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"github.com/danielsussa/tmp_tree/examples/defaultdb"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommands(t *testing.T) {
+	dir := t.TempDir()
+	trained := filepath.Join(dir, "trained")
+	exported := filepath.Join(dir, "export.jsonl")
+	imported := filepath.Join(dir, "imported.db")
+
+	var out bytes.Buffer
+	assert.NoError(t, run([]string{"train", "-db", "diskv", "-path", trained, "-iterations", "100", "-report", "none", "-seed", "1"}, nil, &out))
+	assert.True(t, strings.HasPrefix(out.String(), "episode 1: score "))
+
+	game, err := lookupGame("tictactoe")
+	assert.NoError(t, err)
+	center, err := game.Decode([]byte("EEEEXEEEE"))
+	assert.NoError(t, err)
+	stateFile := filepath.Join(dir, "state")
+	assert.NoError(t, ioutil.WriteFile(stateFile, []byte("EEEEXEEEE\n"), 0644))
+	for _, flag := range [][]string{{"-state", "EEEEXEEEE"}, {"-state-file", stateFile}} {
+		out.Reset()
+		args := append([]string{"inspect", "-db", "diskv", "-path", trained}, flag...)
+		assert.NoError(t, run(args, strings.NewReader("quit\n"), &out))
+		assert.Equal(t, center.ID()+" /> ", out.String())
+	}
+	assert.Error(t, run([]string{"inspect", "-db", "memory", "-state", "EEEEZEEEE"}, strings.NewReader(""), &out))
+
+	out.Reset()
+	assert.NoError(t, run([]string{"export", "-db", "diskv", "-path", trained, "-o", exported}, nil, &out))
+	assert.NoError(t, run([]string{"import", "-db", "sqlite", "-path", imported, "-i", exported}, nil, &out))
+	assert.NoError(t, run([]string{"merge", "-db", "sqlite", "-path", imported, "-from-db", "diskv", "-from-path", trained}, nil, &out))
+
+	var trainedStats, mergedStats bytes.Buffer
+	assert.NoError(t, run([]string{"stats", "-db", "diskv", "-path", trained}, nil, &trainedStats))
+	assert.NoError(t, run([]string{"stats", "-db", "sqlite", "-path", imported}, nil, &mergedStats))
+	// merging the store into a copy of itself doubles the visits
+	trainedLines := strings.Split(trainedStats.String(), "\n")
+	mergedLines := strings.Split(mergedStats.String(), "\n")
+	assert.Equal(t, trainedLines[0], mergedLines[0])
+	assert.NotEqual(t, trainedLines[2], mergedLines[2])
+
+	out.Reset()
+	assert.NoError(t, run([]string{"play", "-db", "sqlite", "-path", imported, "-iterations", "10", "-seed", "1"}, nil, &out))
+	assert.Contains(t, out.String(), "score ")
+
+	assert.Error(t, run([]string{"play", "-game", "chess"}, nil, &out))
+	// a store has to be chosen, a memory one only plays with a search
+	assert.Error(t, run([]string{"train", "-iterations", "10"}, nil, &out))
+	assert.Error(t, run([]string{"play", "-db", "memory"}, nil, &out))
+	assert.NoError(t, run([]string{"play", "-db", "memory", "-iterations", "10", "-seed", "1"}, nil, &out))
+	_, err = defaultdb.NewSQLiteDB(filepath.Join(dir, "missing", "tree.db"))
+	assert.Error(t, err)
+	assert.Error(t, run([]string{"unknown"}, nil, &out))
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	tree "github.com/danielsussa/tmp_tree"
+	"github.com/danielsussa/tmp_tree/examples/defaultdb"
+	// the games selectable with -game register themselves
+	_ "github.com/danielsussa/tmp_tree/examples/g2048"
+	_ "github.com/danielsussa/tmp_tree/examples/labyrinth_game"
+	_ "github.com/danielsussa/tmp_tree/examples/tic-tac-toe"
+)
+
+func lookupGame(name string) (tree.Game, error) {
+	game, ok := tree.LookupGame(name)
+	if !ok {
+		return tree.Game{}, fmt.Errorf("unknown game %q", name)
+	}
+	return game, nil
+}
+
+var backendNames = []string{"memory", "badger", "diskv", "sqlite"}
+
+type store interface {
+	tree.IterableDatabase
+	Close() error
+}
+
+type memoryStore struct {
+	tree.DefaultMemoryDB
+}
+
+func (memoryStore) Close() error {
+	return nil
+}
+
+func openStore(backend, path string) (store, error) {
+	if backend == "" {
+		return nil, fmt.Errorf("no store selected, set -db and -path")
+	}
+	if backend != "memory" && path == "" {
+		return nil, fmt.Errorf("backend %s needs a -path", backend)
+	}
+	switch backend {
+	case "memory":
+		return memoryStore{tree.NewMemoryDB()}, nil
+	case "badger":
+		return defaultdb.NewBadgerDB(path)
+	case "diskv":
+		return defaultdb.NewDefaultDiskDB(path), nil
+	case "sqlite":
+		return defaultdb.NewSQLiteDB(path)
+	}
+	return nil, fmt.Errorf("unknown backend %q", backend)
+}
+
+// storeFlags register the flags selecting a store, prefixed for commands
+// that open more than one. There is no default, a memory store loses what
+// was trained and has nothing to play from.
+func storeFlags(fs *flag.FlagSet, prefix, role string) (backend, path *string) {
+	backend = fs.String(prefix+"db", "", fmt.Sprintf("backend of the %s store: memory, badger, diskv or sqlite", role))
+	path = fs.String(prefix+"path", "", fmt.Sprintf("location of the %s store", role))
+	return backend, path
+}
@@ -0,0 +1,47 @@
+package tree
+
+type CyclePolicy int
+
+const (
+	// CycleIgnore keep playing, the game is responsible for ending
+	CycleIgnore CyclePolicy = iota
+	// CycleDraw end the simulation with a score of 0
+	CycleDraw
+	// CyclePenalize end the simulation with StateTreeConfig.CyclePenalty
+	CyclePenalize
+	// CycleForbid play another action whenever one goes back to a state
+	// of the simulation, it ends as a draw when no other action is left
+	CycleForbid
+)
+
+func (config StateTreeConfig) cycleScore() float64 {
+	if config.CyclePolicy == CyclePenalize {
+		return config.CyclePenalty
+	}
+	return 0
+}
+
+func (n *Node) without(excluded *Action) *Node {
+	actions := make([]*Action, 0, len(n.Actions))
+	for _, action := range n.Actions {
+		if action != excluded {
+			actions = append(actions, action)
+		}
+	}
+	return &Node{Actions: actions, id: n.id}
+}
+
+// distinctActions return the actions of the path counting only once those
+// played more than once
+func (sim *simulation) distinctActions() []*Action {
+	seen := make(map[*Action]bool, len(sim.actionList))
+	actions := make([]*Action, 0, len(sim.actionList))
+	for _, action := range sim.actionList {
+		if seen[action] {
+			continue
+		}
+		seen[action] = true
+		actions = append(actions, action)
+	}
+	return actions
+}
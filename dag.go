@@ -0,0 +1,158 @@
+package tree
+
+import "sort"
+
+// Backup is the rule used to backpropagate a result, nodes are shared by
+// every move order reaching the same State.ID() so the search is a DAG
+type Backup int
+
+const (
+	// BackupPath update only the actions played during the simulation
+	BackupPath Backup = iota
+	// BackupAllParents also update every known action leading into a node
+	// of the simulation
+	BackupAllParents
+	// BackupChildValue set the value of each action on the path to the
+	// value of the nodes it leads to (UCT3)
+	BackupChildValue
+)
+
+// edge is an action played from the parent node
+type edge struct {
+	parent string
+	action string
+}
+
+// dag hold the edges seen since the StateTree was created, they are only
+// kept in memory
+type dag struct {
+	children map[edge]map[string]bool
+	parents  map[string]map[edge]bool
+}
+
+func newDAG() *dag {
+	return &dag{
+		children: make(map[edge]map[string]bool, 0),
+		parents:  make(map[string]map[edge]bool, 0),
+	}
+}
+
+func (d *dag) add(e edge, child string) {
+	if d.children[e] == nil {
+		d.children[e] = make(map[string]bool, 0)
+	}
+	d.children[e][child] = true
+	if d.parents[child] == nil {
+		d.parents[child] = make(map[edge]bool, 0)
+	}
+	d.parents[child][e] = true
+}
+
+func (n *Node) action(id string) *Action {
+	for _, action := range n.Actions {
+		if action.ID == id {
+			return action
+		}
+	}
+	return nil
+}
+
+func (n *Node) totalScore() float64 {
+	total := 0.0
+	for _, action := range n.Actions {
+		total += action.Score
+	}
+	return total
+}
+
+// reachedNodes return the nodes the simulation arrived to after each action
+func (sim *simulation) reachedNodes() []*Node {
+	reached := make([]*Node, 0)
+	if len(sim.nodeList) > 1 {
+		reached = append(reached, sim.nodeList[1:]...)
+	}
+	if sim.leaf != nil {
+		reached = append(reached, sim.leaf)
+	}
+	return reached
+}
+
+// backupAllParents credit every other known edge into a node of the
+// simulation with the discounted value of that node, the reward of those
+// edges is never observed so it is left out
+func (st *StateTree) backupAllParents(sim *simulation, returns []float64, config StateTreeConfig, nodeMap map[string]*Node) {
+	updated := make(map[edge]bool, 0)
+	for i, node := range sim.nodeList {
+		updated[edge{parent: node.id, action: sim.actionList[i].ID}] = true
+	}
+
+	for j, node := range sim.reachedNodes() {
+		value := sim.score
+		if j+1 < len(returns) {
+			value = returns[j+1]
+		}
+		for e := range st.dag.parents[node.id] {
+			if updated[e] {
+				continue
+			}
+			updated[e] = true
+
+			parent, ok := st.findNode(e.parent, nodeMap)
+			if !ok {
+				continue
+			}
+			action := parent.action(e.action)
+			if action == nil {
+				continue
+			}
+			action.NVisited++
+			action.Score += st.scale(parent, config.discount()*value, config)
+			nodeMap[parent.id] = parent
+			sim.touched = append(sim.touched, parent)
+		}
+	}
+}
+
+// backupChildValue set the value of each action of the path to its reward
+// plus the discounted value of the nodes it leads to
+func (st *StateTree) backupChildValue(sim *simulation, config StateTreeConfig, nodeMap map[string]*Node) {
+	for i := len(sim.actionList) - 1; i >= 0; i-- {
+		action := sim.actionList[i]
+		value, ok := st.childValue(edge{parent: sim.nodeList[i].id, action: action.ID}, nodeMap)
+		if !ok {
+			continue
+		}
+		if i < len(sim.rewards) {
+			value = sim.rewards[i] + config.discount()*value
+		} else {
+			value = config.discount() * value
+		}
+		action.Score = value * float64(action.NVisited)
+	}
+}
+
+// childValue is the mean score of the nodes the edge leads to, weighted by
+// their visits
+func (st *StateTree) childValue(e edge, nodeMap map[string]*Node) (float64, bool) {
+	ids := make([]string, 0, len(st.dag.children[e]))
+	for id := range st.dag.children[e] {
+		ids = append(ids, id)
+	}
+	// summed in a fixed order to keep seeded searches reproducible
+	sort.Strings(ids)
+
+	score := 0.0
+	visits := 0
+	for _, id := range ids {
+		child, ok := st.findNode(id, nodeMap)
+		if !ok {
+			continue
+		}
+		score += child.totalScore()
+		visits += child.nVisited()
+	}
+	if visits == 0 {
+		return 0, false
+	}
+	return score / float64(visits), true
+}
@@ -0,0 +1,16 @@
+package tree
+
+// EvaluableState can be implemented by states able to estimate their score
+// before the game ends, it is used when a simulation reaches MaxDepth
+type EvaluableState interface {
+	Evaluate() float64
+}
+
+// evaluate fall back to the game result of the state when it can't be
+// evaluated
+func evaluate(state State) float64 {
+	if evaluable, ok := state.(EvaluableState); ok {
+		return evaluable.Evaluate()
+	}
+	return float64(state.GameResult().Score)
+}
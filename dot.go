@@ -0,0 +1,100 @@
+package tree
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"strings"
+)
+
+type DotConfig struct {
+	// MaxDepth number of actions rendered below the root, 0 means no limit
+	MaxDepth int
+	// MinVisits actions visited less than this are pruned
+	MinVisits int
+}
+
+type dotWriter struct {
+	st     *StateTree
+	config DotConfig
+	// rand of the side effects replayed, the one of the tree is left to
+	// the searches
+	rand *rand.Rand
+	b    strings.Builder
+	// dot IDs by node ID
+	ids   map[string]string
+	count int
+}
+
+// WriteDot render the tree below the state in Graphviz DOT format. Children
+// are found by replaying actions on copies of the state, so with random side
+// effects only one of the possible children is rendered for each action.
+// Those side effects use a source of their own with a fixed seed, rendering
+// does not change what the next searches of a seeded tree do.
+func (st *StateTree) WriteDot(w io.Writer, s State, config DotConfig) error {
+	d := &dotWriter{st: st, config: config, rand: rand.New(rand.NewSource(1)), ids: make(map[string]string, 0)}
+	d.b.WriteString("digraph tree {\n")
+	d.walk(s.Copy(), 0)
+	d.b.WriteString("}\n")
+	_, err := io.WriteString(w, d.b.String())
+	return err
+}
+
+// walk render the node of the state and its children, returning its dot ID
+func (d *dotWriter) walk(state State, depth int) string {
+	node, ok := d.st.findNode(state.ID(), d.st.retained)
+	if !ok {
+		return d.leaf(state, "unexplored")
+	}
+	if id, ok := d.ids[node.id]; ok {
+		return id
+	}
+	id := d.nextID()
+	d.ids[node.id] = id
+	d.b.WriteString(fmt.Sprintf("  %s [label=%q];\n", id, fmt.Sprintf("%s\nn=%d", node.id, node.nVisited())))
+
+	if d.config.MaxDepth > 0 && depth >= d.config.MaxDepth {
+		return id
+	}
+	for _, action := range node.Actions {
+		if action.NVisited < d.config.MinVisits {
+			continue
+		}
+		child := state.Copy()
+		child.PlayAction(action.ID)
+		playSideEffectsWith(child, d.rand)
+
+		var childID string
+		if child.TurnResult(TurnRequest{Depth: depth + 1, Rand: d.rand}).EndGame {
+			childID = d.leaf(child, fmt.Sprintf("end\nscore=%d", child.GameResult().Score))
+		} else {
+			childID = d.walk(child, depth+1)
+		}
+		d.b.WriteString(fmt.Sprintf("  %s -> %s [label=%q];\n", id, childID, d.edgeLabel(action)))
+	}
+	return id
+}
+
+func (d *dotWriter) nextID() string {
+	d.count++
+	return fmt.Sprintf("n%d", d.count-1)
+}
+
+func (d *dotWriter) leaf(state State, label string) string {
+	id := d.nextID()
+	d.b.WriteString(fmt.Sprintf("  %s [shape=box,label=%q];\n", id, fmt.Sprintf("%s\n%s", state.ID(), label)))
+	return id
+}
+
+func (d *dotWriter) edgeLabel(action *Action) string {
+	if action.NVisited == 0 {
+		return fmt.Sprintf("%s\nn=0", action.ID)
+	}
+	mean := action.Score / float64(action.NVisited)
+	ucb := fSelection(action.Score, action.NVisited, d.st.stats.NVisited)
+	if math.IsNaN(ucb) {
+		ucb = mean
+	}
+	return fmt.Sprintf("%s\nn=%d mean=%.3f ucb=%.3f", action.ID, action.NVisited, mean, ucb)
+}
@@ -0,0 +1,72 @@
+package tree
+
+import "fmt"
+
+// Evaluation is what an Evaluator estimates for a single state
+type Evaluation struct {
+	// Value expected GameResult.Score from the state
+	Value float64
+	// Priors by action ID, stored on the node as Action.Prior, including
+	// for the actions added later by progressive widening
+	Priors map[string]float64
+}
+
+// Evaluator replace playing until the end of the game: the first time a
+// state is reached its node is expanded with the returned priors and the
+// value is backpropagated instead of a game result. Evaluate receive up to
+// StateTreeConfig.EvaluatorBatch states and must return one Evaluation for
+// each of them, in the same order, training panics otherwise.
+type Evaluator interface {
+	Evaluate(states []State) []Evaluation
+}
+
+func (st *StateTree) SetEvaluator(e Evaluator) *StateTree {
+	st.evaluator = e
+	return st
+}
+
+func (config StateTreeConfig) evaluatorBatch() int {
+	if config.EvaluatorBatch < 1 {
+		return 1
+	}
+	return config.EvaluatorBatch
+}
+
+// evaluateLeaves send every distinct leaf reached by the simulations in a
+// single call to the evaluator
+func (st *StateTree) evaluateLeaves(sims []*simulation) {
+	states := make([]State, 0)
+	index := make(map[string]int, 0)
+	for _, sim := range sims {
+		if sim.leaf == nil {
+			continue
+		}
+		if _, ok := index[sim.leaf.id]; ok {
+			continue
+		}
+		index[sim.leaf.id] = len(states)
+		states = append(states, sim.state)
+	}
+	if len(states) == 0 {
+		return
+	}
+
+	evaluations := st.evaluator.Evaluate(states)
+	if len(evaluations) != len(states) {
+		panic(fmt.Sprintf("tree: evaluator returned %d evaluations for %d states", len(evaluations), len(states)))
+	}
+	for _, sim := range sims {
+		if sim.leaf == nil {
+			continue
+		}
+		evaluation := evaluations[index[sim.leaf.id]]
+		// progressive widening add the remaining actions later on
+		sim.leaf.priors = evaluation.Priors
+		for _, action := range sim.leaf.Actions {
+			if prior, ok := evaluation.Priors[action.ID]; ok {
+				action.Prior = prior
+			}
+		}
+		sim.score = evaluation.Value
+	}
+}
@@ -0,0 +1,143 @@
+package tree
+
+import (
+	"sync"
+	"time"
+)
+
+type EventKind int
+
+const (
+	// IterationStarted a simulation starts from the root state
+	IterationStarted EventKind = iota
+	// NodeExpanded a node was created for a state seen for the first time
+	NodeExpanded
+	// ActionSelected an action was played, State is the state after it
+	ActionSelected
+	// Backpropagated the result of a simulation was backpropagated
+	Backpropagated
+	// GameFinished a simulation reached the end of the game
+	GameFinished
+	// StoreFlushed the nodes visited by a batch of simulations were written
+	StoreFlushed
+	// TrainingStopped Train or Search ended, Reason tells why
+	TrainingStopped
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case IterationStarted:
+		return "iteration_started"
+	case NodeExpanded:
+		return "node_expanded"
+	case ActionSelected:
+		return "action_selected"
+	case Backpropagated:
+		return "backpropagated"
+	case GameFinished:
+		return "game_finished"
+	case StoreFlushed:
+		return "store_flushed"
+	case TrainingStopped:
+		return "training_stopped"
+	}
+	return "unknown"
+}
+
+// Event is emitted by the training loop, only the fields relevant to its
+// Kind are set
+type Event struct {
+	Kind    EventKind
+	State   State
+	NodeID  string
+	Actions []*Action
+	Action  *Action
+	// Depth number of actions played in the simulation
+	Depth int
+	// Score result of the simulation
+	Score float64
+	// Nodes number of nodes written to the store
+	Nodes int
+	// Elapsed time spent writing to the store
+	Elapsed time.Duration
+	Reason  StopReason
+}
+
+type subscriber struct {
+	id    int
+	kinds map[EventKind]bool
+	f     func(Event)
+}
+
+// Subscribe call f for every event of the given kinds, or of every kind when
+// none is given, until the returned function is called
+func (st *StateTree) Subscribe(f func(Event), kinds ...EventKind) (unsubscribe func()) {
+	st.subMu.Lock()
+	defer st.subMu.Unlock()
+	st.lastSubID++
+	sub := &subscriber{id: st.lastSubID, f: f}
+	if len(kinds) > 0 {
+		sub.kinds = make(map[EventKind]bool, len(kinds))
+		for _, kind := range kinds {
+			sub.kinds[kind] = true
+		}
+	}
+	st.subscribers = append(st.subscribers, sub)
+
+	return func() {
+		st.subMu.Lock()
+		defer st.subMu.Unlock()
+		for i, s := range st.subscribers {
+			if s.id == sub.id {
+				// copied, emit may be ranging over the previous slice
+				st.subscribers = append(st.subscribers[:i:i], st.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Events is Subscribe delivering to a channel, training blocks while the
+// buffer is full. The channel is closed on stop, which can be called from
+// any goroutine, events emitted meanwhile are dropped.
+func (st *StateTree) Events(buffer int, kinds ...EventKind) (<-chan Event, func()) {
+	ch := make(chan Event, buffer)
+	done := make(chan struct{})
+	// senders hold the read lock, so ch is never closed during a send
+	var mu sync.RWMutex
+	closed := false
+	unsubscribe := st.Subscribe(func(e Event) {
+		mu.RLock()
+		defer mu.RUnlock()
+		if closed {
+			return
+		}
+		select {
+		case ch <- e:
+		case <-done:
+		}
+	}, kinds...)
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			close(done)
+			unsubscribe()
+			mu.Lock()
+			closed = true
+			close(ch)
+			mu.Unlock()
+		})
+	}
+}
+
+func (st *StateTree) emit(e Event) {
+	st.subMu.Lock()
+	subscribers := st.subscribers
+	st.subMu.Unlock()
+	for _, sub := range subscribers {
+		if sub.kinds == nil || sub.kinds[e.Kind] {
+			sub.f(e)
+		}
+	}
+}
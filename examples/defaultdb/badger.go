@@ -2,7 +2,6 @@ package defaultdb
 
 import (
 	"github.com/dgraph-io/badger/v3"
-	"log"
 )
 
 type BadgerDB struct {
@@ -38,14 +37,36 @@ func (dmp BadgerDB) Add(key, value string) error {
 	return err
 }
 
-func NewBadgerDB(filename string) BadgerDB {
+func NewBadgerDB(filename string) (BadgerDB, error) {
 	// Open the Badger database located in the /tmp/badger directory.
 	// It will be created if it doesn't exist.
 	options := badger.DefaultOptions(filename)
 	options.Logger = nil
 	db, err := badger.Open(options)
 	if err != nil {
-		log.Fatal(err)
+		return BadgerDB{}, err
 	}
-	return BadgerDB{db: db}
+	return BadgerDB{db: db}, nil
+}
+
+func (dmp BadgerDB) Each(f func(key, value string) error) error {
+	return dmp.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			valCopy, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			if err := f(string(item.Key()), string(valCopy)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (dmp BadgerDB) Close() error {
+	return dmp.db.Close()
 }
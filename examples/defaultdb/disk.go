@@ -37,3 +37,22 @@ func NewDefaultDiskDB(filename string) DiskPersistence {
 	})
 	return DiskPersistence{d: d}
 }
+
+func (dmp DiskPersistence) Each(f func(key, value string) error) error {
+	cancel := make(chan struct{})
+	defer close(cancel)
+	for key := range dmp.d.Keys(cancel) {
+		b, err := dmp.d.Read(key)
+		if err != nil {
+			return err
+		}
+		if err := f(key, string(b)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (dmp DiskPersistence) Close() error {
+	return nil
+}
@@ -0,0 +1,58 @@
+package defaultdb
+
+import (
+	"database/sql"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type SQLiteDB struct {
+	db *sql.DB
+}
+
+func (dmp SQLiteDB) Find(key string) (string, bool) {
+	value := ""
+	err := dmp.db.QueryRow("SELECT value FROM nodes WHERE key = ?", key).Scan(&value)
+	if err != nil {
+		return "", false
+	}
+	return value, true
+}
+
+func (dmp SQLiteDB) Add(key, value string) error {
+	_, err := dmp.db.Exec("INSERT OR REPLACE INTO nodes (key, value) VALUES (?, ?)", key, value)
+	return err
+}
+
+func (dmp SQLiteDB) Each(f func(key, value string) error) error {
+	rows, err := dmp.db.Query("SELECT key, value FROM nodes ORDER BY key")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return err
+		}
+		if err := f(key, value); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (dmp SQLiteDB) Close() error {
+	return dmp.db.Close()
+}
+
+func NewSQLiteDB(filename string) (SQLiteDB, error) {
+	db, err := sql.Open("sqlite3", filename)
+	if err != nil {
+		return SQLiteDB{}, err
+	}
+	if _, err := db.Exec("CREATE TABLE IF NOT EXISTS nodes (key TEXT PRIMARY KEY, value TEXT NOT NULL)"); err != nil {
+		db.Close()
+		return SQLiteDB{}, err
+	}
+	return SQLiteDB{db: db}, nil
+}
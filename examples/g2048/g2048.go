@@ -5,6 +5,7 @@ import (
 	tree "github.com/danielsussa/tmp_tree"
 	"math/rand"
 	"sort"
+	"strings"
 )
 
 type g2048 struct {
@@ -40,12 +41,17 @@ func (g g2048) PossibleActions() []string {
 
 func print2048(board []int, score int) {
 	fmt.Print("\033[H\033[2J")
-	fmt.Println(fmt.Sprintf("------- %v --------", score))
+	fmt.Print(render2048(board, score))
+}
+
+func render2048(board []int, score int) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("------- %v --------\n", score))
 	for i := 0; i < 4; i++ {
 		k := i * 4
-		fmt.Print(fmt.Sprintf("%-6d %-6d %-6d %-6d", board[0+k], board[1+k], board[2+k], board[3+k]))
-		fmt.Println()
+		b.WriteString(fmt.Sprintf("%-6d %-6d %-6d %-6d\n", board[0+k], board[1+k], board[2+k], board[3+k]))
 	}
+	return b.String()
 }
 
 func convertScalar(board []int) []int {
@@ -192,6 +198,10 @@ func (g g2048) PlaySideEffects() {
 	addNumberOnBoard(g.board)
 }
 
+func (g g2048) PlaySideEffectsWith(req tree.SideEffectsRequest) {
+	addRandomNumber(g.board, req.Rand.Intn, req.Rand.Float64)
+}
+
 func (g g2048) TurnResult(r tree.TurnRequest) tree.TurnResult {
 	iters := len(g.PossibleActions())
 	return tree.TurnResult{
@@ -199,6 +209,38 @@ func (g g2048) TurnResult(r tree.TurnRequest) tree.TurnResult {
 	}
 }
 
+// Prior favor the actions that keep the max tile in a corner
+func (g g2048) Prior(action string) float64 {
+	total := 0.0
+	prior := 0.0
+	for _, possible := range g.PossibleActions() {
+		weight := 1.0
+		next := g.Copy().(*g2048)
+		next.PlayAction(possible)
+		if maxTileInCorner(next.board) {
+			weight = 3
+		}
+		total += weight
+		if possible == action {
+			prior = weight
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return prior / total
+}
+
+func maxTileInCorner(board []int) bool {
+	max := 0
+	for _, val := range board {
+		if val > max {
+			max = val
+		}
+	}
+	return board[0] == max || board[3] == max || board[12] == max || board[15] == max
+}
+
 func (g g2048) GameResult() tree.GameResult {
 	return tree.GameResult{
 		Score: g.score,
@@ -216,13 +258,17 @@ func getFreePlaces(board []int) []int {
 }
 
 func addNumberOnBoard(board []int) {
+	addRandomNumber(board, rand.Intn, rand.Float64)
+}
+
+func addRandomNumber(board []int, intn func(int) int, randFloat func() float64) {
 	freePlaces := getFreePlaces(board)
 	if len(freePlaces) == 0 {
 		return
 	}
 
-	freePlace := freePlaces[rand.Intn(len(freePlaces))]
-	fRand := rand.Float64()
+	freePlace := freePlaces[intn(len(freePlaces))]
+	fRand := randFloat()
 	val := 2
 	if fRand >= 0.9 {
 		val = 4
@@ -285,3 +331,8 @@ func startNewGame() *g2048 {
 func newArr() []int {
 	return make([]int, 16)
 }
+
+// NewGame start a game with a random tile on the board
+func NewGame() tree.State {
+	return startNewGame()
+}
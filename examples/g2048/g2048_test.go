@@ -9,7 +9,10 @@ import (
 )
 
 func TestTrain2048(t *testing.T) {
-	defaultDb := defaultdb.NewBadgerDB("/media/kanczuk/146D-1AFD/dataset2/game2048")
+	defaultDb, err := defaultdb.NewBadgerDB("/media/kanczuk/146D-1AFD/dataset2/game2048")
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	stateTree := tree.New().SetDB(defaultDb)
 	fmt.Println("starting")
@@ -72,3 +75,29 @@ func TestPlay2048(t *testing.T) {
 	}
 	print2048(game.board, game.score)
 }
+
+// trainAndPlay train before every turn of the game, for at most turns turns
+func trainAndPlay(config tree.StateTreeConfig, turns int) {
+	rand.Seed(1)
+	game := startNewGame()
+	stateTree := tree.New()
+	for i := 0; i < turns; i++ {
+		stateTree.Train(game, config)
+		if stateTree.PlayTurn(game) {
+			break
+		}
+		game.PlaySideEffects()
+		if len(game.PossibleActions()) == 0 {
+			break
+		}
+	}
+	print2048(game.board, game.score)
+}
+
+func TestPlay2048ReuseTree(t *testing.T) {
+	trainAndPlay(tree.StateTreeConfig{MaxIterations: 100, ReuseTree: true}, 10)
+}
+
+func TestPlay2048NormalizeGlobal(t *testing.T) {
+	trainAndPlay(tree.StateTreeConfig{MaxIterations: 100, Normalize: tree.NormalizeGlobal}, 10)
+}
@@ -1,6 +1,8 @@
 package g2048
 
 import (
+	tree "github.com/danielsussa/tmp_tree"
+	"github.com/danielsussa/tmp_tree/internal/treetest"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -348,3 +350,33 @@ func TestMerge(t *testing.T) {
 		assert.Equal(t, score, 0)
 	}
 }
+
+func TestPrior(t *testing.T) {
+	game := g2048{
+		board: []int{
+			0, 8, 0, 0,
+			0, 0, 0, 0,
+			0, 0, 0, 0,
+			0, 0, 2, 0,
+		},
+	}
+	assert.Equal(t, 0.375, game.Prior("L"))
+	assert.Equal(t, 0.375, game.Prior("R"))
+	assert.Equal(t, 0.125, game.Prior("U"))
+	assert.Equal(t, 0.125, game.Prior("D"))
+	assert.Equal(t, 0.0, game.Prior("X"))
+}
+
+func TestRegisteredGame(t *testing.T) {
+	game, ok := tree.LookupGame("g2048")
+	assert.True(t, ok)
+
+	state := &g2048{board: []int{2, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 4}, score: 8}
+	data, err := game.Encode(state)
+	assert.NoError(t, err)
+	decoded, err := game.Decode(data)
+	assert.NoError(t, err)
+	assert.Equal(t, game.Render(state), game.Render(decoded))
+
+	assert.Equal(t, "not a 2048 state: *treetest.Countdown", game.Render(&treetest.Countdown{N: 1}))
+}
@@ -0,0 +1,49 @@
+package g2048
+
+import (
+	"encoding/json"
+	"fmt"
+	tree "github.com/danielsussa/tmp_tree"
+)
+
+func init() {
+	tree.RegisterGame(tree.Game{
+		Name:   "g2048",
+		New:    NewGame,
+		Encode: encode,
+		Decode: decode,
+		Render: render,
+	})
+}
+
+func render(s tree.State) string {
+	game, ok := s.(*g2048)
+	if !ok {
+		return fmt.Sprintf("not a 2048 state: %T", s)
+	}
+	return render2048(game.board, game.score)
+}
+
+type encodedGame struct {
+	Board []int `json:"board"`
+	Score int   `json:"score"`
+}
+
+func encode(s tree.State) ([]byte, error) {
+	game, ok := s.(*g2048)
+	if !ok {
+		return nil, fmt.Errorf("not a 2048 state: %T", s)
+	}
+	return json.Marshal(encodedGame{Board: game.board, Score: game.score})
+}
+
+func decode(data []byte) (tree.State, error) {
+	var encoded encodedGame
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return nil, err
+	}
+	if len(encoded.Board) != 16 {
+		return nil, fmt.Errorf("a board has 16 places, got %d", len(encoded.Board))
+	}
+	return &g2048{board: encoded.Board, score: encoded.Score}, nil
+}
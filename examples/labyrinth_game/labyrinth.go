@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	tree "github.com/danielsussa/tmp_tree"
+	"strings"
 )
 
 func labyrinthMap() [][]string {
@@ -147,17 +148,22 @@ func (g *game) PlayAction(action string) {
 }
 
 func (g game) Print() {
+	fmt.Println(g.render())
+}
+
+func (g game) render() string {
+	var b strings.Builder
 	for j, _ := range g.PlaceMap {
 		for i, _ := range g.PlaceMap[j] {
-			fmt.Print(g.PlaceMap[j][i] + "   ")
+			b.WriteString(g.PlaceMap[j][i] + "   ")
 		}
-		fmt.Print("    ")
+		b.WriteString("    ")
 		for i, _ := range g.PlaceMap[j] {
-			fmt.Print(g.PlayerMap[j][i] + "   ")
+			b.WriteString(g.PlayerMap[j][i] + "   ")
 		}
-		fmt.Println()
+		b.WriteString("\n")
 	}
-	fmt.Println()
+	return b.String()
 }
 
 func (g *game) PlaySideEffects() {
@@ -197,3 +203,8 @@ func newGame() *game {
 		MaxMoves:  25,
 	}
 }
+
+// NewGame start a game at the entrance of the labyrinth
+func NewGame() tree.State {
+	return newGame()
+}
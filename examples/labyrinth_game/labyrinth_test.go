@@ -3,6 +3,8 @@ package labyrinth
 import (
 	"fmt"
 	tree "github.com/danielsussa/tmp_tree"
+	"github.com/danielsussa/tmp_tree/internal/treetest"
+	"github.com/stretchr/testify/assert"
 	"testing"
 )
 
@@ -62,3 +64,48 @@ func TestLabyrinthWithTrain(t *testing.T) {
 	}
 	game.Print()
 }
+
+// trainAndPlay train before every turn of the game, for at most turns turns
+func trainAndPlay(game *game, config tree.StateTreeConfig, turns int) {
+	stateTree := tree.New()
+	for i := 0; i < turns; i++ {
+		stateTree.Train(game, config)
+		if stateTree.PlayTurn(game) {
+			break
+		}
+	}
+	game.Print()
+}
+
+func TestLabyrinthWithTrainReuseTree(t *testing.T) {
+	trainAndPlay(newGame(), tree.StateTreeConfig{MaxIterations: 100, ReuseTree: true}, 20)
+}
+
+func TestLabyrinthWithTrainCycleForbid(t *testing.T) {
+	trainAndPlay(newGame(), tree.StateTreeConfig{MaxIterations: 100, CyclePolicy: tree.CycleForbid}, 20)
+}
+
+func TestRegisteredGame(t *testing.T) {
+	game, ok := tree.LookupGame("labyrinth")
+	assert.True(t, ok)
+
+	state := game.New()
+	data, err := game.Encode(state)
+	assert.NoError(t, err)
+	decoded, err := game.Decode(data)
+	assert.NoError(t, err)
+	assert.Equal(t, state.ID(), decoded.ID())
+	assert.Equal(t, state.PossibleActions(), decoded.PossibleActions())
+
+	for _, data := range []string{
+		`{"PlaceMap":[["#","#","#"],["#","P","#"],["#","#","#"]]}`,
+		`{"PlaceMap":[["#","#","#"],["#","P","#"],["#","#","#"]],"PlayerMap":[["#","#","#"],["#"," ","#"]]}`,
+		`{"PlaceMap":[["#","#","#"],["#"," ","#"],["#","#","#"]],"PlayerMap":[["#","#","#"],["#"," ","#"],["#","#","#"]]}`,
+		`{"PlaceMap":[["P","#"],["#","#"]],"PlayerMap":[["P","#"],["#","#"]]}`,
+	} {
+		_, err := game.Decode([]byte(data))
+		assert.Error(t, err, data)
+	}
+
+	assert.Equal(t, "not a labyrinth state: *treetest.Countdown", game.Render(&treetest.Countdown{N: 1}))
+}
@@ -0,0 +1,66 @@
+package labyrinth
+
+import (
+	"encoding/json"
+	"fmt"
+	tree "github.com/danielsussa/tmp_tree"
+)
+
+func init() {
+	tree.RegisterGame(tree.Game{
+		Name:   "labyrinth",
+		New:    NewGame,
+		Encode: encode,
+		Decode: decode,
+		Render: render,
+	})
+}
+
+func render(s tree.State) string {
+	g, ok := s.(*game)
+	if !ok {
+		return fmt.Sprintf("not a labyrinth state: %T", s)
+	}
+	return g.render()
+}
+
+func encode(s tree.State) ([]byte, error) {
+	g, ok := s.(*game)
+	if !ok {
+		return nil, fmt.Errorf("not a labyrinth state: %T", s)
+	}
+	return json.Marshal(g)
+}
+
+func decode(data []byte) (tree.State, error) {
+	g := &game{}
+	if err := json.Unmarshal(data, g); err != nil {
+		return nil, err
+	}
+	if len(g.PlaceMap) == 0 {
+		return nil, fmt.Errorf("missing PlaceMap")
+	}
+	if len(g.PlayerMap) != len(g.PlaceMap) {
+		return nil, fmt.Errorf("PlayerMap has %d rows, PlaceMap %d", len(g.PlayerMap), len(g.PlaceMap))
+	}
+	player := false
+	for j, row := range g.PlaceMap {
+		if len(g.PlayerMap[j]) != len(row) {
+			return nil, fmt.Errorf("row %d of PlayerMap has %d places, PlaceMap %d", j, len(g.PlayerMap[j]), len(row))
+		}
+		for i, place := range row {
+			if place != "P" {
+				continue
+			}
+			// the moves look at the four neighbours of the player
+			if j == 0 || j == len(g.PlaceMap)-1 || i == 0 || i == len(row)-1 {
+				return nil, fmt.Errorf("player on the border at %d,%d", j, i)
+			}
+			player = true
+		}
+	}
+	if !player {
+		return nil, fmt.Errorf("missing player in PlaceMap")
+	}
+	return g, nil
+}
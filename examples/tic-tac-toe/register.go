@@ -0,0 +1,53 @@
+package tictactoe
+
+import (
+	"fmt"
+	tree "github.com/danielsussa/tmp_tree"
+	"strings"
+)
+
+func init() {
+	tree.RegisterGame(tree.Game{
+		Name:   "tictactoe",
+		New:    NewGame,
+		Encode: encode,
+		Decode: decode,
+		Render: render,
+	})
+}
+
+func render(s tree.State) string {
+	game, ok := s.(ticTacGame)
+	if !ok {
+		return fmt.Sprintf("not a tic-tac-toe state: %T", s)
+	}
+	return game.render()
+}
+
+// encode the board as one letter per place, row by row
+func encode(s tree.State) ([]byte, error) {
+	game, ok := s.(ticTacGame)
+	if !ok {
+		return nil, fmt.Errorf("not a tic-tac-toe state: %T", s)
+	}
+	var b strings.Builder
+	for _, place := range game.board {
+		b.WriteString(string(place))
+	}
+	return []byte(b.String()), nil
+}
+
+func decode(data []byte) (tree.State, error) {
+	if len(data) != 9 {
+		return nil, fmt.Errorf("a board has 9 places, got %d", len(data))
+	}
+	board := make([]player, 0, 9)
+	for _, c := range string(data) {
+		p := player(c)
+		if p != E && p != X && p != O {
+			return nil, fmt.Errorf("unknown player %q", c)
+		}
+		board = append(board, p)
+	}
+	return ticTacGame{board: board}, nil
+}
@@ -92,7 +92,23 @@ func (t ticTacGame) ID() string {
 }
 
 func (t ticTacGame) PlaySideEffects() {
-	t.randomMove(O)
+	t.randomMove(O, rand.Intn)
+}
+
+func (t ticTacGame) PlaySideEffectsWith(req tree.SideEffectsRequest) {
+	t.randomMove(O, req.Rand.Intn)
+}
+
+func (t ticTacGame) SideEffectOutcomes() []tree.State {
+	outcomes := make([]tree.State, 0)
+	for idx, place := range t.board {
+		if place == E {
+			outcome := t.Copy().(ticTacGame)
+			outcome.move(idx, O)
+			outcomes = append(outcomes, outcome)
+		}
+	}
+	return outcomes
 }
 
 func (t ticTacGame) TurnResult(req tree.TurnRequest) tree.TurnResult {
@@ -115,7 +131,7 @@ func (t ticTacGame) GameResult() tree.GameResult {
 	return tree.GameResult{Score: t.winner().toScore()}
 }
 
-func (t ticTacGame) randomMove(p player) bool {
+func (t ticTacGame) randomMove(p player, intn func(int) int) bool {
 	free := make([]int, 0)
 
 	for idx, place := range t.board {
@@ -126,18 +142,34 @@ func (t ticTacGame) randomMove(p player) bool {
 	if len(free) == 0 {
 		return false
 	}
-	place := free[rand.Intn(len(free))]
+	place := free[intn(len(free))]
 	t.board[place] = p
 	return true
 }
 
 func (t ticTacGame) print() {
 	fmt.Println("----------------------")
-	fmt.Println(fmt.Sprintf("%s|%s|%s", t.board[0], t.board[1], t.board[2]))
-	fmt.Println(fmt.Sprintf("%s|%s|%s", t.board[3], t.board[4], t.board[5]))
-	fmt.Println(fmt.Sprintf("%s|%s|%s", t.board[6], t.board[7], t.board[8]))
+	fmt.Print(t.render())
+}
+
+func (t ticTacGame) render() string {
+	return fmt.Sprintf("%s|%s|%s\n%s|%s|%s\n%s|%s|%s\n",
+		t.board[0], t.board[1], t.board[2],
+		t.board[3], t.board[4], t.board[5],
+		t.board[6], t.board[7], t.board[8])
 }
 
 func (t ticTacGame) move(idx int, p player) {
 	t.board[idx] = p
 }
+
+// NewGame start a game on an empty board, X plays first
+func NewGame() tree.State {
+	return ticTacGame{
+		board: []player{
+			E, E, E,
+			E, E, E,
+			E, E, E,
+		},
+	}
+}
@@ -2,6 +2,7 @@ package tictactoe
 
 import (
 	tree "github.com/danielsussa/tmp_tree"
+	"github.com/danielsussa/tmp_tree/internal/treetest"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -21,7 +22,7 @@ func TestFirstSecondMoveMove(t *testing.T) {
 		E, E, E,
 	}
 
-	stateTree := tree.New()
+	stateTree := tree.New().Seed(1)
 	stateTree.Train(game, tree.StateTreeConfig{
 		MaxIterations: 5000,
 	})
@@ -45,7 +46,7 @@ func TestBestSecondMoveMove(t *testing.T) {
 		E, E, E,
 	}
 
-	stateTree := tree.New()
+	stateTree := tree.New().Seed(1)
 	stateTree.Train(game, tree.StateTreeConfig{
 		MaxIterations: 1000,
 	})
@@ -69,7 +70,7 @@ func TestDontLoseMoveMove(t *testing.T) {
 		E, E, E,
 	}
 
-	stateTree := tree.New()
+	stateTree := tree.New().Seed(1)
 	stateTree.Train(game, tree.StateTreeConfig{
 		MaxIterations: 1000,
 	})
@@ -77,3 +78,104 @@ func TestDontLoseMoveMove(t *testing.T) {
 
 	assert.Equal(t, expected, game.board)
 }
+
+func TestSolveTicTacToe(t *testing.T) {
+	game := ticTacGame{
+		board: []player{
+			E, E, E,
+			E, E, E,
+			E, E, E,
+		},
+	}
+
+	stateTree := tree.New().Seed(1)
+	stateTree.Train(game, tree.StateTreeConfig{
+		MaxIterations: 100000,
+		Solver:        true,
+	})
+
+	assert.Equal(t, tree.ProvenDraw, stateTree.Proof(game))
+}
+
+func TestSolveForcedWin(t *testing.T) {
+	game := ticTacGame{
+		board: []player{
+			X, O, E,
+			E, X, E,
+			O, E, E,
+		},
+	}
+
+	stateTree := tree.New().Seed(1)
+	stateTree.Train(game, tree.StateTreeConfig{
+		MaxIterations: 1000,
+		Solver:        true,
+	})
+
+	assert.Equal(t, tree.ProvenWin, stateTree.Proof(game))
+}
+
+func TestSolveDontLose(t *testing.T) {
+	game := ticTacGame{
+		board: []player{
+			O, E, O,
+			E, X, E,
+			E, E, E,
+		},
+	}
+
+	expected := []player{
+		O, X, O,
+		E, X, E,
+		E, E, E,
+	}
+
+	stateTree := tree.New().Seed(1)
+	stateTree.Train(game, tree.StateTreeConfig{
+		MaxIterations: 10000,
+		Solver:        true,
+	})
+	stateTree.PlayTurn(game)
+
+	assert.Equal(t, expected, game.board)
+}
+
+func TestSeededTrain(t *testing.T) {
+	search := func() tree.SearchResult {
+		game := ticTacGame{
+			board: []player{
+				E, E, E,
+				E, E, E,
+				E, E, E,
+			},
+		}
+		return tree.New().Seed(7).Search(game, tree.StateTreeConfig{
+			MaxIterations: 2000,
+		})
+	}
+
+	first, second := search(), search()
+	assert.Equal(t, first.Actions, second.Actions)
+	assert.Equal(t, first.PrincipalVariation, second.PrincipalVariation)
+}
+
+func TestRegisteredGame(t *testing.T) {
+	game, ok := tree.LookupGame("tictactoe")
+	assert.True(t, ok)
+
+	state := game.New()
+	state.PlayAction("4")
+	data, err := game.Encode(state)
+	assert.NoError(t, err)
+	assert.Equal(t, "EEEEXEEEE", string(data))
+
+	decoded, err := game.Decode(data)
+	assert.NoError(t, err)
+	assert.Equal(t, state.ID(), decoded.ID())
+	assert.Equal(t, "E|E|E\nE|X|E\nE|E|E\n", game.Render(decoded))
+
+	_, err = game.Decode([]byte("EEEEZEEEE"))
+	assert.Error(t, err)
+
+	assert.Equal(t, "not a tic-tac-toe state: *treetest.Countdown", game.Render(&treetest.Countdown{N: 1}))
+}
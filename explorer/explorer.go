@@ -0,0 +1,213 @@
+// Package explorer navigate a trained tree interactively, printing the
+// statistics of each node as tables and charts.
+package explorer
+
+import (
+	"bufio"
+	"fmt"
+	tree "github.com/danielsussa/tmp_tree"
+	"github.com/pterm/pterm"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const exploreHelp = `commands:
+  ls            table of the actions of the current node
+  chart         bar chart of the visits of each action
+  cd <action>   play the action, by ID or by its rank in ls
+  up            go back to the parent state
+  root          go back to the root state
+  pv            show the principal variation from the current state
+  next [n]      follow the principal variation for n actions (default 1)
+  help          show this help
+  quit          leave the explorer
+`
+
+type explorer struct {
+	st   *tree.StateTree
+	rand *rand.Rand
+	out  io.Writer
+	// states from the root to the current one, path[i] is reached by
+	// playing actions[i-1] from path[i-1]
+	path    []tree.State
+	actions []string
+}
+
+// Explore navigate the tree stored in the database from the state, reading
+// one command per line from in and writing tables and charts to out. Nothing
+// is trained, so any Database backend set with SetDB can be explored. rng is
+// given to the random side effects of the actions played, seeded from the
+// clock when nil.
+func Explore(st *tree.StateTree, s tree.State, rng *rand.Rand, in io.Reader, out io.Writer) error {
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	e := &explorer{st: st, rand: rng, out: out, path: []tree.State{s.Copy()}}
+	e.prompt()
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 0 {
+			if fields[0] == "quit" || fields[0] == "exit" {
+				return nil
+			}
+			if err := e.run(fields[0], fields[1:]); err != nil {
+				fmt.Fprintf(out, "error: %s\n", err)
+			}
+		}
+		e.prompt()
+	}
+	return scanner.Err()
+}
+
+func (e *explorer) run(cmd string, args []string) error {
+	switch cmd {
+	case "ls":
+		return e.table()
+	case "chart":
+		return e.chart()
+	case "cd":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: cd <action>")
+		}
+		return e.cd(args[0])
+	case "up", "..":
+		if len(e.actions) == 0 {
+			return fmt.Errorf("already at the root")
+		}
+		e.path = e.path[:len(e.path)-1]
+		e.actions = e.actions[:len(e.actions)-1]
+	case "root":
+		e.path = e.path[:1]
+		e.actions = e.actions[:0]
+	case "pv":
+		fmt.Fprintln(e.out, strings.Join(e.st.PrincipalVariation(e.current()), " "))
+	case "next":
+		n := 1
+		if len(args) > 0 {
+			var err error
+			if n, err = strconv.Atoi(args[0]); err != nil {
+				return fmt.Errorf("invalid count %q", args[0])
+			}
+		}
+		return e.next(n)
+	case "help":
+		fmt.Fprint(e.out, exploreHelp)
+	default:
+		return fmt.Errorf("unknown command %q, type help", cmd)
+	}
+	return nil
+}
+
+func (e *explorer) current() tree.State {
+	return e.path[len(e.path)-1]
+}
+
+func (e *explorer) prompt() {
+	fmt.Fprintf(e.out, "%s /%s> ", e.current().ID(), strings.Join(e.actions, "/"))
+}
+
+// stats of the actions of the current node, from the most visited to the
+// least visited
+func (e *explorer) stats() ([]tree.ActionStats, error) {
+	stats, ok := e.st.NodeStats(e.current().ID())
+	if !ok || len(stats) == 0 {
+		return nil, fmt.Errorf("state %s was never explored", e.current().ID())
+	}
+	return stats, nil
+}
+
+func (e *explorer) table() error {
+	actions, err := e.stats()
+	if err != nil {
+		return err
+	}
+	data := [][]string{{"#", "action", "visits", "mean", "lower", "upper", "proof", "prior"}}
+	for i, stats := range actions {
+		data = append(data, []string{
+			strconv.Itoa(i),
+			stats.ID,
+			strconv.Itoa(stats.NVisited),
+			fmt.Sprintf("%.3f", stats.Mean),
+			fmt.Sprintf("%.3f", stats.Lower),
+			fmt.Sprintf("%.3f", stats.Upper),
+			stats.Proof.String(),
+			fmt.Sprintf("%.3f", stats.Prior),
+		})
+	}
+	table, err := pterm.DefaultTable.WithHasHeader().WithData(data).Srender()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(e.out, table)
+	return nil
+}
+
+func (e *explorer) chart() error {
+	actions, err := e.stats()
+	if err != nil {
+		return err
+	}
+	bars := make(pterm.Bars, 0, len(actions))
+	for _, stats := range actions {
+		bars = append(bars, pterm.Bar{Label: stats.ID, Value: stats.NVisited})
+	}
+	chart, err := pterm.DefaultBarChart.WithBars(bars).WithHorizontal().WithShowValue().Srender()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(e.out, chart)
+	return nil
+}
+
+// cd play the action on a copy of the current state, an index refers to
+// the order of the actions in ls
+func (e *explorer) cd(arg string) error {
+	actions, err := e.stats()
+	if err != nil {
+		return err
+	}
+	for _, stats := range actions {
+		if stats.ID == arg {
+			e.play(arg)
+			return nil
+		}
+	}
+	if i, err := strconv.Atoi(arg); err == nil && i >= 0 && i < len(actions) {
+		e.play(actions[i].ID)
+		return nil
+	}
+	return fmt.Errorf("unknown action %q", arg)
+}
+
+func (e *explorer) next(n int) error {
+	for i := 0; i < n; i++ {
+		line := e.st.PrincipalVariation(e.current())
+		if len(line) == 0 {
+			if i == 0 {
+				return fmt.Errorf("no principal variation from %s", e.current().ID())
+			}
+			return nil
+		}
+		e.play(line[0])
+	}
+	return nil
+}
+
+func (e *explorer) play(action string) {
+	state := e.current().Copy()
+	state.PlayAction(action)
+	if random, ok := state.(tree.RandomState); ok {
+		random.PlaySideEffectsWith(tree.SideEffectsRequest{Rand: e.rand})
+	} else {
+		state.PlaySideEffects()
+	}
+	e.path = append(e.path, state)
+	e.actions = append(e.actions, action)
+	if state.TurnResult(tree.TurnRequest{Depth: len(e.actions), Rand: e.rand}).EndGame {
+		fmt.Fprintf(e.out, "game over, score %d\n", state.GameResult().Score)
+	}
+}
@@ -0,0 +1,25 @@
+package explorer
+
+import (
+	"bytes"
+	tree "github.com/danielsussa/tmp_tree"
+	"github.com/danielsussa/tmp_tree/internal/treetest"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestExplore(t *testing.T) {
+	stateTree := tree.New().Seed(1)
+	stateTree.Train(&treetest.Countdown{N: 4}, tree.StateTreeConfig{MaxIterations: 100})
+
+	var out bytes.Buffer
+	commands := "ls\ncd 2\nup\ncd 1\nroot\nnext 2\nup\ncd nowhere\nquit\n"
+	assert.NoError(t, Explore(stateTree, &treetest.Countdown{N: 4}, nil, strings.NewReader(commands), &out))
+	lines := out.String()
+	assert.Contains(t, lines, "action")
+	// cd 1 is the second most visited action
+	assert.Contains(t, lines, "4 /> 2 /2> 4 /> 3 /1> 4 /> game over, score 1\n0 /2/2> 2 /2> ")
+	assert.Contains(t, lines, "error: unknown action \"nowhere\"")
+	assert.True(t, strings.HasSuffix(lines, "> "))
+}
@@ -3,10 +3,12 @@ module github.com/danielsussa/tmp_tree
 go 1.16
 
 require (
-	github.com/dgraph-io/badger/v3 v3.2103.2 // indirect
+	github.com/dgraph-io/badger/v3 v3.2103.2
 	github.com/google/btree v1.0.1 // indirect
-	github.com/mattn/go-sqlite3 v1.14.8 // indirect
-	github.com/peterbourgon/diskv v2.0.1+incompatible // indirect
-	github.com/pterm/pterm v0.12.31 // indirect
-	github.com/stretchr/testify v1.7.0 // indirect
+	github.com/mattn/go-sqlite3 v1.14.8
+	github.com/peterbourgon/diskv v2.0.1+incompatible
+	github.com/pterm/pterm v0.12.31
+	github.com/stretchr/testify v1.7.0
+	google.golang.org/grpc v1.40.0
+	google.golang.org/protobuf v1.27.1
 )
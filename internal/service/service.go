@@ -0,0 +1,40 @@
+// Package service hold the configuration shared by the server and rpc
+// packages.
+package service
+
+import (
+	tree "github.com/danielsussa/tmp_tree"
+	"time"
+)
+
+// Config configure the servers answering searches with a shared
+// StateTree
+type Config struct {
+	// Search base configuration of the searches, MaxIterations and
+	// MaxTimeout are the defaults when a request does not set them
+	Search tree.StateTreeConfig
+	// MaxIterations and MaxTimeout bound what a request can ask for,
+	// no bound when zero
+	MaxIterations int
+	MaxTimeout    time.Duration
+}
+
+// Request apply the iterations and timeout asked by a request to the base
+// configuration, zero keeps the default, within the bounds of the service
+func (c Config) Request(iterations int, timeout time.Duration) tree.StateTreeConfig {
+	config := c.Search
+	if iterations > 0 {
+		config.MaxIterations = iterations
+	}
+	if c.MaxIterations > 0 && (config.MaxIterations == 0 || config.MaxIterations > c.MaxIterations) {
+		config.MaxIterations = c.MaxIterations
+	}
+	if timeout > 0 {
+		config.MaxTimeout = &timeout
+	}
+	if c.MaxTimeout > 0 && (config.MaxTimeout == nil || *config.MaxTimeout > c.MaxTimeout) {
+		maxTimeout := c.MaxTimeout
+		config.MaxTimeout = &maxTimeout
+	}
+	return config
+}
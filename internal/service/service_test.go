@@ -0,0 +1,28 @@
+package service
+
+import (
+	tree "github.com/danielsussa/tmp_tree"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRequest(t *testing.T) {
+	service := Config{Search: tree.StateTreeConfig{MaxIterations: 10}, MaxIterations: 100, MaxTimeout: time.Second}
+
+	config := service.Request(0, 0)
+	assert.Equal(t, 10, config.MaxIterations)
+	assert.Equal(t, time.Second, *config.MaxTimeout)
+
+	config = service.Request(1000, time.Minute)
+	assert.Equal(t, 100, config.MaxIterations)
+	assert.Equal(t, time.Second, *config.MaxTimeout)
+
+	config = service.Request(50, time.Millisecond)
+	assert.Equal(t, 50, config.MaxIterations)
+	assert.Equal(t, time.Millisecond, *config.MaxTimeout)
+
+	config = Config{}.Request(0, 0)
+	assert.Equal(t, 0, config.MaxIterations)
+	assert.Nil(t, config.MaxTimeout)
+}
@@ -0,0 +1,57 @@
+// Package treetest hold the small states shared by the tests of the
+// packages built on top of the tree.
+package treetest
+
+import (
+	"fmt"
+	tree "github.com/danielsussa/tmp_tree"
+	"strconv"
+	"strings"
+)
+
+// Countdown remove one or two from N, reaching exactly zero wins and going
+// below it loses
+type Countdown struct {
+	N int
+}
+
+func (c *Countdown) ID() string                { return strconv.Itoa(c.N) }
+func (c *Countdown) PossibleActions() []string { return []string{"1", "2"} }
+func (c *Countdown) Copy() tree.State          { return &Countdown{N: c.N} }
+func (c *Countdown) PlaySideEffects()          {}
+func (c *Countdown) GameResult() tree.GameResult {
+	if c.N == 0 {
+		return tree.GameResult{Score: 1}
+	}
+	return tree.GameResult{Score: -1}
+}
+
+func (c *Countdown) PlayAction(action string) {
+	take, _ := strconv.Atoi(action)
+	c.N -= take
+}
+
+func (c *Countdown) TurnResult(req tree.TurnRequest) tree.TurnResult {
+	return tree.TurnResult{EndGame: c.N <= 0}
+}
+
+// CountdownGame describe Countdown from 4, it is not registered
+var CountdownGame = tree.Game{
+	Name:   "countdown",
+	New:    func() tree.State { return &Countdown{N: 4} },
+	Encode: encodeCountdown,
+	Decode: decodeCountdown,
+	Render: func(s tree.State) string { return s.ID() },
+}
+
+func encodeCountdown(s tree.State) ([]byte, error) {
+	return []byte(s.ID()), nil
+}
+
+func decodeCountdown(data []byte) (tree.State, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not a number: %q", data)
+	}
+	return &Countdown{N: n}, nil
+}
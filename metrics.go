@@ -0,0 +1,194 @@
+package tree
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// DefaultLatencyBuckets upper bounds in seconds of the store latency
+	// histograms
+	DefaultLatencyBuckets = []float64{0.00001, 0.0001, 0.001, 0.01, 0.1, 1}
+	// DefaultLengthBuckets upper bounds of the game length histogram
+	DefaultLengthBuckets = []float64{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000}
+	// DefaultScoreBuckets upper bounds of the playout score histogram
+	DefaultScoreBuckets = []float64{-1, 0, 1, 10, 100, 1000, 10000, 100000}
+)
+
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *histogram) observe(v float64) {
+	for i, bound := range h.buckets {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+func (h *histogram) write(w io.Writer, name, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	for i, bound := range h.buckets {
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", name, formatFloat(bound), h.counts[i])
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, h.count)
+	fmt.Fprintf(w, "%s_sum %s\n%s_count %d\n", name, formatFloat(h.sum), name, h.count)
+}
+
+// Metrics collect counters and histograms of the training loop, exposed in
+// the Prometheus text format. It is safe to serve them while training.
+type Metrics struct {
+	mu           sync.Mutex
+	playouts     uint64
+	nodesCreated uint64
+	findHits     uint64
+	findMisses   uint64
+	adds         uint64
+	addErrors    uint64
+	// lookups answered by the nodes kept in memory, without reaching the store
+	cacheHits   uint64
+	cacheMisses uint64
+	findLatency *histogram
+	addLatency  *histogram
+	gameLength  *histogram
+	score       *histogram
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		findLatency: newHistogram(DefaultLatencyBuckets),
+		addLatency:  newHistogram(DefaultLatencyBuckets),
+		gameLength:  newHistogram(DefaultLengthBuckets),
+		score:       newHistogram(DefaultScoreBuckets),
+	}
+}
+
+// SetMetrics record what the training loop does in m, nil stops recording
+func (st *StateTree) SetMetrics(m *Metrics) *StateTree {
+	if st.unsubscribeMetrics != nil {
+		st.unsubscribeMetrics()
+		st.unsubscribeMetrics = nil
+	}
+	st.metrics = m
+	if m != nil {
+		st.unsubscribeMetrics = st.Subscribe(m.observe, NodeExpanded, Backpropagated)
+	}
+	return st
+}
+
+func (m *Metrics) observe(e Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch e.Kind {
+	case NodeExpanded:
+		m.nodesCreated++
+	case Backpropagated:
+		m.playouts++
+		m.gameLength.observe(float64(e.Depth))
+		m.score.observe(e.Score)
+	}
+}
+
+func (m *Metrics) observeFind(found bool, elapsed time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if found {
+		m.findHits++
+	} else {
+		m.findMisses++
+	}
+	m.findLatency.observe(elapsed.Seconds())
+}
+
+func (m *Metrics) observeAdd(err error, elapsed time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.adds++
+	if err != nil {
+		m.addErrors++
+	}
+	m.addLatency.observe(elapsed.Seconds())
+}
+
+func (m *Metrics) observeCache(hit bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if hit {
+		m.cacheHits++
+	} else {
+		m.cacheMisses++
+	}
+}
+
+// WriteTo write the metrics in the Prometheus text exposition format
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	m.mu.Lock()
+	counter := func(name, help string, v uint64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", name, help, name, name, v)
+	}
+	counter("sktree_playouts_total", "Playouts backpropagated.", m.playouts)
+	counter("sktree_nodes_created_total", "Nodes created for states seen for the first time.", m.nodesCreated)
+	fmt.Fprintf(&b, "# HELP sktree_db_finds_total Lookups sent to the store.\n# TYPE sktree_db_finds_total counter\n")
+	fmt.Fprintf(&b, "sktree_db_finds_total{result=\"hit\"} %d\nsktree_db_finds_total{result=\"miss\"} %d\n", m.findHits, m.findMisses)
+	counter("sktree_db_adds_total", "Nodes written to the store.", m.adds)
+	counter("sktree_db_add_errors_total", "Writes to the store that failed.", m.addErrors)
+	counter("sktree_cache_hits_total", "Lookups answered by the nodes kept in memory.", m.cacheHits)
+	counter("sktree_cache_misses_total", "Lookups that had to reach the store.", m.cacheMisses)
+	ratio := 0.0
+	if lookups := m.cacheHits + m.cacheMisses; lookups > 0 {
+		ratio = float64(m.cacheHits) / float64(lookups)
+	}
+	fmt.Fprintf(&b, "# HELP sktree_cache_hit_ratio Share of lookups answered by the nodes kept in memory.\n")
+	fmt.Fprintf(&b, "# TYPE sktree_cache_hit_ratio gauge\nsktree_cache_hit_ratio %s\n", formatFloat(ratio))
+	m.findLatency.write(&b, "sktree_db_find_seconds", "Latency of the store lookups.")
+	m.addLatency.write(&b, "sktree_db_add_seconds", "Latency of the store writes.")
+	m.gameLength.write(&b, "sktree_game_length", "Actions played by a playout.")
+	m.score.write(&b, "sktree_score", "Score of a playout.")
+	m.mu.Unlock()
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// ServeHTTP serve the metrics to a Prometheus scraper
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	_, _ = m.WriteTo(w)
+}
+
+// find read the store, recording the lookup when metrics are set
+func (st *StateTree) find(key string) (string, bool) {
+	if st.metrics == nil {
+		return st.db.Find(key)
+	}
+	start := time.Now()
+	val, ok := st.db.Find(key)
+	st.metrics.observeFind(ok, time.Since(start))
+	return val, ok
+}
+
+// add write to the store, recording the write when metrics are set
+func (st *StateTree) add(key, val string) error {
+	if st.metrics == nil {
+		return st.db.Add(key, val)
+	}
+	start := time.Now()
+	err := st.db.Add(key, val)
+	st.metrics.observeAdd(err, time.Since(start))
+	return err
+}
@@ -0,0 +1,117 @@
+package tree
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Normalization int
+
+const (
+	NormalizeNone Normalization = iota
+	// NormalizeGlobal scale with the min and max returns seen by the whole
+	// tree, they are stored under boundsKey
+	NormalizeGlobal
+	// NormalizeNode scale with the min and max returns seen from each node,
+	// they are stored with the node
+	NormalizeNode
+)
+
+const boundsKey = "#bounds"
+
+// bounds are the running min and max of the observed returns
+type bounds struct {
+	min float64
+	max float64
+}
+
+func newBounds(v float64) *bounds {
+	return &bounds{min: v, max: v}
+}
+
+func (b *bounds) update(v float64) {
+	if v < b.min {
+		b.min = v
+	}
+	if v > b.max {
+		b.max = v
+	}
+}
+
+// scale v into [0,1], while every return seen is the same it stays in the middle
+func (b *bounds) scale(v float64) float64 {
+	if b.max == b.min {
+		return 0.5
+	}
+	return (v - b.min) / (b.max - b.min)
+}
+
+func (b *bounds) toDB() string {
+	return fmt.Sprintf("%s,%s", formatFloat(b.min), formatFloat(b.max))
+}
+
+func parseBounds(val string) *bounds {
+	valSpl := strings.Split(val, ",")
+	if len(valSpl) != 2 {
+		return nil
+	}
+	min, _ := strconv.ParseFloat(valSpl[0], 64)
+	max, _ := strconv.ParseFloat(valSpl[1], 64)
+	return &bounds{min: min, max: max}
+}
+
+func (st *StateTree) normalize(sim *simulation, returns []float64, config StateTreeConfig) []float64 {
+	normalized := make([]float64, len(returns))
+	switch config.Normalize {
+	case NormalizeGlobal:
+		for _, r := range returns {
+			st.updateGlobalBounds(r)
+		}
+		for i, r := range returns {
+			normalized[i] = st.bounds.scale(r)
+		}
+	case NormalizeNode:
+		for i, r := range returns {
+			node := sim.nodeList[i]
+			if node.bounds == nil {
+				node.bounds = newBounds(r)
+			}
+			node.bounds.update(r)
+			normalized[i] = node.bounds.scale(r)
+		}
+	default:
+		copy(normalized, returns)
+	}
+	return normalized
+}
+
+// scale normalize a single return credited to the node
+func (st *StateTree) scale(node *Node, r float64, config StateTreeConfig) float64 {
+	switch config.Normalize {
+	case NormalizeGlobal:
+		st.updateGlobalBounds(r)
+		return st.bounds.scale(r)
+	case NormalizeNode:
+		if node.bounds == nil {
+			node.bounds = newBounds(r)
+		}
+		node.bounds.update(r)
+		return node.bounds.scale(r)
+	}
+	return r
+}
+
+// updateGlobalBounds load the bounds stored by a previous run before the
+// first update
+func (st *StateTree) updateGlobalBounds(r float64) {
+	if st.bounds == nil {
+		if val, ok := st.find(boundsKey); ok {
+			st.bounds = parseBounds(val)
+		}
+	}
+	if st.bounds == nil {
+		st.bounds = newBounds(r)
+	}
+	st.bounds.update(r)
+}
@@ -0,0 +1,73 @@
+package tree
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PriorState can be implemented by states with a domain heuristic, the
+// prior of every action is computed once when its node is created and then
+// stored with the node. Priors are expected to sum to 1 when used by PUCT.
+type PriorState interface {
+	Prior(action string) float64
+}
+
+func statePrior(state State, action string) float64 {
+	if prior, ok := state.(PriorState); ok {
+		return prior.Prior(action)
+	}
+	return 0
+}
+
+// prior of an action of the node, the evaluator priors come before the
+// ones of the state
+func (n *Node) prior(state State, action string) float64 {
+	if prior, ok := n.priors[action]; ok {
+		return prior
+	}
+	return statePrior(state, action)
+}
+
+func puctSelection(action *Action, nodeVisited int, c float64) float64 {
+	exploitation := 0.0
+	if action.NVisited > 0 {
+		exploitation = action.Score / float64(action.NVisited)
+	}
+	exploration := c * action.Prior * math.Sqrt(float64(nodeVisited)) / float64(1+action.NVisited)
+	return exploitation + exploration
+}
+
+func progressiveBias(action *Action, weight float64) float64 {
+	return weight * action.Prior / float64(action.NVisited+1)
+}
+
+// pendingPriors encode the priors of the actions the node does not have yet
+// as "action=prior" pairs, sorted by action
+func (n Node) pendingPriors() string {
+	pending := make([]string, 0)
+	for actionID, prior := range n.priors {
+		if n.action(actionID) == nil {
+			pending = append(pending, actionID+"="+formatFloat(prior))
+		}
+	}
+	sort.Strings(pending)
+	return strings.Join(pending, ",")
+}
+
+func parsePriors(val string) map[string]float64 {
+	priors := make(map[string]float64, 0)
+	for _, pair := range strings.Split(val, ",") {
+		idx := strings.LastIndex(pair, "=")
+		if idx < 0 {
+			continue
+		}
+		prior, err := strconv.ParseFloat(pair[idx+1:], 64)
+		if err != nil {
+			continue
+		}
+		priors[pair[:idx]] = prior
+	}
+	return priors
+}
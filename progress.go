@@ -0,0 +1,201 @@
+package tree
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// DefaultReportEvery number of playouts between two progress reports
+const DefaultReportEvery = 100
+
+// Progress of a Train or PlayGame call, the averages cover the playouts
+// since the previous report
+type Progress struct {
+	Playouts int
+	Elapsed  time.Duration
+	// PlayoutsPerSecond since the previous report
+	PlayoutsPerSecond float64
+	// NodesCreated number of nodes created since the call started
+	NodesCreated int
+	// AvgGameLength average number of actions played by a playout
+	AvgGameLength float64
+	// MeanScore moving average of the playout scores
+	MeanScore float64
+	// BestAction most visited action of the root, BestActionStable is the
+	// number of consecutive reports it has been the best one
+	BestAction       string
+	BestActionStable int
+	// StoreLatency average time spent writing a batch of nodes to the store
+	StoreLatency time.Duration
+	// Done is set on the last report of the call, with the StopReason of
+	// Train and Search
+	Done       bool
+	StopReason StopReason
+}
+
+type Reporter interface {
+	Report(p Progress)
+}
+
+// ReporterFunc adapt a function to the Reporter interface
+type ReporterFunc func(p Progress)
+
+func (f ReporterFunc) Report(p Progress) {
+	f(p)
+}
+
+// SetReporter receive progress reports every StateTreeConfig.ReportEvery
+// playouts during Train, Search and PlayGame
+func (st *StateTree) SetReporter(r Reporter) *StateTree {
+	st.reporter = r
+	return st
+}
+
+func (config StateTreeConfig) reportEvery() int {
+	if config.ReportEvery <= 0 {
+		return DefaultReportEvery
+	}
+	return config.ReportEvery
+}
+
+type progressTracker struct {
+	st       *StateTree
+	reporter Reporter
+	root     State
+	every    int
+	start    time.Time
+	last     time.Time
+	p        Progress
+	// accumulated since the previous report
+	playouts  int
+	depths    int
+	scores    float64
+	flushes   int
+	flushTime time.Duration
+}
+
+// trackProgress report to the reporter until the returned function is
+// called, which sends the last report
+func (st *StateTree) trackProgress(s State, config StateTreeConfig) (stop func()) {
+	reporter := config.Reporter
+	if reporter == nil {
+		reporter = st.reporter
+	}
+	if reporter == nil {
+		return func() {}
+	}
+	t := &progressTracker{st: st, reporter: reporter, root: s, every: config.reportEvery(), start: time.Now()}
+	t.last = t.start
+	unsubscribe := st.Subscribe(t.observe, NodeExpanded, Backpropagated, StoreFlushed, TrainingStopped)
+	return func() {
+		unsubscribe()
+		t.p.Done = true
+		t.report()
+	}
+}
+
+func (t *progressTracker) observe(e Event) {
+	switch e.Kind {
+	case NodeExpanded:
+		t.p.NodesCreated++
+	case Backpropagated:
+		t.p.Playouts++
+		t.playouts++
+		t.depths += e.Depth
+		t.scores += e.Score
+	case StoreFlushed:
+		t.flushes++
+		t.flushTime += e.Elapsed
+		// reports are only sent once the nodes are written, so the root
+		// statistics include every playout counted
+		if t.playouts >= t.every {
+			t.report()
+		}
+	case TrainingStopped:
+		t.p.StopReason = e.Reason
+	}
+}
+
+func (t *progressTracker) report() {
+	now := time.Now()
+	t.p.Elapsed = now.Sub(t.start)
+	if t.playouts > 0 {
+		if interval := now.Sub(t.last).Seconds(); interval > 0 {
+			t.p.PlayoutsPerSecond = float64(t.playouts) / interval
+		}
+		t.p.AvgGameLength = float64(t.depths) / float64(t.playouts)
+		t.p.MeanScore = t.scores / float64(t.playouts)
+	}
+	if t.flushes > 0 {
+		t.p.StoreLatency = t.flushTime / time.Duration(t.flushes)
+	}
+	if node, ok := t.st.findNode(t.root.ID(), t.st.retained); ok && len(node.Actions) > 0 {
+		best := bestAction(node).ID
+		if best == t.p.BestAction {
+			t.p.BestActionStable++
+		} else {
+			t.p.BestAction = best
+			t.p.BestActionStable = 1
+		}
+	}
+	t.reporter.Report(t.p)
+
+	t.last = now
+	t.playouts, t.depths, t.scores = 0, 0, 0
+	t.flushes, t.flushTime = 0, 0
+}
+
+// TerminalReporter rewrite a single status line on every report
+type TerminalReporter struct {
+	w io.Writer
+}
+
+func NewTerminalReporter(w io.Writer) *TerminalReporter {
+	return &TerminalReporter{w: w}
+}
+
+func (r *TerminalReporter) Report(p Progress) {
+	fmt.Fprintf(r.w, "\r\033[K%d playouts (%.0f/s) | new nodes %d | length %.1f | score %.3f | best %s x%d | store %s",
+		p.Playouts, p.PlayoutsPerSecond, p.NodesCreated, p.AvgGameLength, p.MeanScore,
+		p.BestAction, p.BestActionStable, p.StoreLatency)
+	if p.Done {
+		fmt.Fprintf(r.w, " | %s after %s\n", p.StopReason, p.Elapsed.Round(time.Millisecond))
+	}
+}
+
+// CSVReporter write a row per report, preceded by a header
+type CSVReporter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func NewCSVReporter(w io.Writer) *CSVReporter {
+	return &CSVReporter{w: csv.NewWriter(w)}
+}
+
+func (r *CSVReporter) Report(p Progress) {
+	if !r.header {
+		r.header = true
+		_ = r.w.Write([]string{
+			"elapsed_ms", "playouts", "playouts_per_second", "nodes_created", "avg_game_length",
+			"mean_score", "best_action", "best_action_stable", "store_latency_us", "done", "stop_reason",
+		})
+	}
+	_ = r.w.Write([]string{
+		strconv.FormatInt(p.Elapsed.Milliseconds(), 10),
+		strconv.Itoa(p.Playouts),
+		formatFloat(p.PlayoutsPerSecond),
+		strconv.Itoa(p.NodesCreated),
+		formatFloat(p.AvgGameLength),
+		formatFloat(p.MeanScore),
+		p.BestAction,
+		strconv.Itoa(p.BestActionStable),
+		strconv.FormatInt(p.StoreLatency.Microseconds(), 10),
+		strconv.FormatBool(p.Done),
+		p.StopReason.String(),
+	})
+	r.w.Flush()
+}
@@ -0,0 +1,36 @@
+package tree
+
+import (
+	"math/rand"
+)
+
+type SideEffectsRequest struct {
+	// Rand source of randomness of the tree, see StateTree.Seed
+	Rand *rand.Rand
+}
+
+// RandomState can be implemented by states with random side effects, the
+// tree then calls PlaySideEffectsWith instead of PlaySideEffects so that
+// searches with the same seed play the same side effects
+type RandomState interface {
+	PlaySideEffectsWith(SideEffectsRequest)
+}
+
+// Seed reset the source of randomness of the tree, Train with the same seed
+// and the same State build the same tree
+func (st *StateTree) Seed(seed int64) *StateTree {
+	st.rand = rand.New(rand.NewSource(seed))
+	return st
+}
+
+func (st *StateTree) playSideEffects(state State) {
+	playSideEffectsWith(state, st.rand)
+}
+
+func playSideEffectsWith(state State, rng *rand.Rand) {
+	if random, ok := state.(RandomState); ok {
+		random.PlaySideEffectsWith(SideEffectsRequest{Rand: rng})
+		return
+	}
+	state.PlaySideEffects()
+}
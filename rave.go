@@ -0,0 +1,51 @@
+package tree
+
+import "math"
+
+// DefaultRaveEquivalence is the number of visits at which regular and
+// AMAF statistics weigh the same in the default RAVE schedule
+const DefaultRaveEquivalence = 500
+
+// RaveSchedule return the "hand-selected" beta schedule
+// sqrt(k / (3n + k)), where n is the number of visits of the action
+func RaveSchedule(k float64) func(action *Action) float64 {
+	return func(action *Action) float64 {
+		return math.Sqrt(k / (3*float64(action.NVisited) + k))
+	}
+}
+
+func (config StateTreeConfig) raveBeta() func(action *Action) float64 {
+	if config.RaveBeta != nil {
+		return config.RaveBeta
+	}
+	return RaveSchedule(DefaultRaveEquivalence)
+}
+
+func raveSelection(action *Action, NVisited int, beta float64) float64 {
+	amaf := 0.0
+	if action.AMAFNVisited > 0 {
+		amaf = action.AMAFScore / float64(action.AMAFNVisited)
+	}
+	// unvisited actions are ranked by their AMAF value alone
+	if action.NVisited == 0 {
+		return amaf + math.Sqrt(2*math.Log(float64(NVisited)))
+	}
+	exploitation := (1-beta)*action.Score/float64(action.NVisited) + beta*amaf
+	exploration := math.Sqrt(2 * math.Log(float64(NVisited)) / float64(action.NVisited))
+	return exploitation + exploration
+}
+
+// updateAMAF credit the return of each node on the path to every action of
+// that node that was played at that node or anywhere after it
+func updateAMAF(nodeList []*Node, actionList []*Action, returns []float64) {
+	played := make(map[string]bool, 0)
+	for i := len(nodeList) - 1; i >= 0; i-- {
+		played[actionList[i].ID] = true
+		for _, action := range nodeList[i].Actions {
+			if played[action.ID] {
+				action.AMAFNVisited++
+				action.AMAFScore += returns[i]
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package tree
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Game describe a game to the tools selecting it by name, packages register
+// theirs with RegisterGame from an init function
+type Game struct {
+	Name string
+	// New return the first state of a game
+	New func() State
+	// Encode and Decode turn a state into bytes and back, so it can be sent
+	// to servers or stored by tools
+	Encode func(State) ([]byte, error)
+	Decode func([]byte) (State, error)
+	// Render a human readable view of the state
+	Render func(State) string
+}
+
+var (
+	gamesMu sync.RWMutex
+	games   = make(map[string]Game)
+)
+
+// RegisterGame make the game available to LookupGame, it panics when the
+// name is already registered or a function is missing
+func RegisterGame(game Game) {
+	gamesMu.Lock()
+	defer gamesMu.Unlock()
+	if game.Name == "" || game.New == nil || game.Encode == nil || game.Decode == nil || game.Render == nil {
+		panic(fmt.Sprintf("tree: incomplete registration of game %q", game.Name))
+	}
+	if _, dup := games[game.Name]; dup {
+		panic(fmt.Sprintf("tree: game %q registered twice", game.Name))
+	}
+	games[game.Name] = game
+}
+
+func LookupGame(name string) (Game, bool) {
+	gamesMu.RLock()
+	defer gamesMu.RUnlock()
+	game, ok := games[name]
+	return game, ok
+}
+
+// Games names of the registered games, sorted
+func Games() []string {
+	gamesMu.RLock()
+	defer gamesMu.RUnlock()
+	names := make([]string, 0, len(games))
+	for name := range games {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,30 @@
+package tree
+
+func (n *Node) addChild(id string) {
+	if n.children == nil {
+		n.children = make(map[string]bool, 0)
+	}
+	n.children[id] = true
+}
+
+// reroot keep in memory only the retained nodes reachable from rootID
+func (st *StateTree) reroot(rootID string) {
+	retained := make(map[string]*Node, 0)
+	queue := []string{rootID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		node, ok := st.retained[id]
+		if !ok {
+			continue
+		}
+		if _, ok := retained[id]; ok {
+			continue
+		}
+		retained[id] = node
+		for child := range node.children {
+			queue = append(queue, child)
+		}
+	}
+	st.retained = retained
+}
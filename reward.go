@@ -0,0 +1,23 @@
+package tree
+
+func (config StateTreeConfig) discount() float64 {
+	if config.Discount <= 0 {
+		return 1
+	}
+	return config.Discount
+}
+
+// returns compute the discounted return of every action of the simulation,
+// the final score is received together with the reward of the last action
+func (sim *simulation) returns(discount float64) []float64 {
+	returns := make([]float64, len(sim.actionList))
+	next := sim.score
+	for i := len(sim.actionList) - 1; i >= 0; i-- {
+		if i < len(sim.rewards) {
+			returns[i] = sim.rewards[i]
+		}
+		returns[i] += next
+		next = discount * returns[i]
+	}
+	return returns
+}
@@ -0,0 +1,64 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	tree "github.com/danielsussa/tmp_tree"
+	"google.golang.org/grpc"
+	"io"
+)
+
+// Client call a remote StateTree, encoding the states with the codec
+type Client struct {
+	rpc   StateTreeClient
+	codec Codec
+}
+
+func NewClient(conn grpc.ClientConnInterface, codec Codec) *Client {
+	return &Client{rpc: NewStateTreeClient(conn), codec: codec}
+}
+
+// Search train the remote tree from the state and rank its actions, zero
+// limits use the defaults of the server
+func (c *Client) Search(ctx context.Context, s tree.State, maxIterations int, timeoutMs int64) (*SearchResponse, error) {
+	state, err := c.codec.Encode(s)
+	if err != nil {
+		return nil, err
+	}
+	return c.rpc.Search(ctx, &SearchRequest{State: state, MaxIterations: int32(maxIterations), TimeoutMs: timeoutMs})
+}
+
+func (c *Client) GetNode(ctx context.Context, id string) (*Node, error) {
+	return c.rpc.GetNode(ctx, &GetNodeRequest{Id: id})
+}
+
+// Train the remote tree from the state, calling f with every progress report
+// until the training stops. The State of req is replaced by the encoded s.
+func (c *Client) Train(ctx context.Context, s tree.State, req *TrainRequest, f func(p *Progress)) error {
+	state, err := c.codec.Encode(s)
+	if err != nil {
+		return err
+	}
+	if req == nil {
+		req = &TrainRequest{}
+	}
+	stream, err := c.rpc.Train(ctx, &TrainRequest{
+		State:         state,
+		MaxIterations: req.MaxIterations,
+		TimeoutMs:     req.TimeoutMs,
+		ReportEvery:   req.ReportEvery,
+	})
+	if err != nil {
+		return err
+	}
+	for {
+		p, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		f(p)
+	}
+}
@@ -0,0 +1,151 @@
+// Package rpc expose a StateTree as the gRPC service of sktree.proto, states
+// travel as opaque bytes turned into tree.State by a Codec on both ends.
+package rpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative sktree.proto
+
+import (
+	"context"
+	tree "github.com/danielsussa/tmp_tree"
+	"github.com/danielsussa/tmp_tree/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sync"
+	"time"
+)
+
+// Codec turn states into the bytes sent over the wire and back
+type Codec interface {
+	Encode(s tree.State) ([]byte, error)
+	Decode(data []byte) (tree.State, error)
+}
+
+// GameCodec use the encoder and decoder of a registered game
+func GameCodec(game tree.Game) Codec {
+	return gameCodec{game: game}
+}
+
+type gameCodec struct {
+	game tree.Game
+}
+
+func (c gameCodec) Encode(s tree.State) ([]byte, error) {
+	return c.game.Encode(s)
+}
+
+func (c gameCodec) Decode(data []byte) (tree.State, error) {
+	return c.game.Decode(data)
+}
+
+// Config of Search and Train
+type Config = service.Config
+
+type Server struct {
+	UnimplementedStateTreeServer
+	// mu serialize the calls using the tree
+	mu     sync.Mutex
+	tree   *tree.StateTree
+	codec  Codec
+	config Config
+}
+
+func NewServer(st *tree.StateTree, codec Codec, config Config) *Server {
+	return &Server{tree: st, codec: codec, config: config}
+}
+
+func (s *Server) Search(ctx context.Context, req *SearchRequest) (*SearchResponse, error) {
+	state, err := s.codec.Decode(req.State)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "decode state: %s", err)
+	}
+	config := s.config.Request(int(req.MaxIterations), time.Duration(req.TimeoutMs)*time.Millisecond)
+	config.Context = ctx
+
+	s.mu.Lock()
+	result := s.tree.Search(state, config)
+	s.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+
+	return &SearchResponse{
+		BestAction:         result.BestAction,
+		Actions:            toActionStats(result.Actions),
+		PrincipalVariation: result.PrincipalVariation,
+		Playouts:           int32(result.Playouts),
+		ElapsedMs:          result.Elapsed.Milliseconds(),
+		NodesVisited:       int32(result.NodesVisited),
+		StopReason:         result.StopReason.String(),
+	}, nil
+}
+
+func (s *Server) GetNode(ctx context.Context, req *GetNodeRequest) (*Node, error) {
+	s.mu.Lock()
+	stats, found := s.tree.NodeStats(req.Id)
+	s.mu.Unlock()
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "node %s not found", req.Id)
+	}
+	return &Node{Id: req.Id, Actions: toActionStats(stats)}, nil
+}
+
+// Train stream the progress reports of the training, it runs until the
+// bounds of the request or until the client goes away
+func (s *Server) Train(req *TrainRequest, stream StateTree_TrainServer) error {
+	state, err := s.codec.Decode(req.State)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "decode state: %s", err)
+	}
+	config := s.config.Request(int(req.MaxIterations), time.Duration(req.TimeoutMs)*time.Millisecond)
+	config.Context = stream.Context()
+	if req.ReportEvery > 0 {
+		config.ReportEvery = int(req.ReportEvery)
+	}
+	var sendErr error
+	config.Reporter = tree.ReporterFunc(func(p tree.Progress) {
+		if sendErr == nil {
+			sendErr = stream.Send(toProgress(p))
+		}
+	})
+
+	s.mu.Lock()
+	s.tree.Train(state, config)
+	s.mu.Unlock()
+	if err := stream.Context().Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	return sendErr
+}
+
+func toActionStats(stats []tree.ActionStats) []*ActionStats {
+	actions := make([]*ActionStats, 0, len(stats))
+	for _, action := range stats {
+		actions = append(actions, &ActionStats{
+			Id:       action.ID,
+			Visits:   int32(action.NVisited),
+			Mean:     action.Mean,
+			Lower:    action.Lower,
+			Upper:    action.Upper,
+			Proof:    action.Proof.String(),
+			Prior:    action.Prior,
+			MaxScore: action.MaxScore,
+		})
+	}
+	return actions
+}
+
+func toProgress(p tree.Progress) *Progress {
+	return &Progress{
+		Playouts:          int32(p.Playouts),
+		ElapsedMs:         p.Elapsed.Milliseconds(),
+		PlayoutsPerSecond: p.PlayoutsPerSecond,
+		NodesCreated:      int32(p.NodesCreated),
+		AvgGameLength:     p.AvgGameLength,
+		MeanScore:         p.MeanScore,
+		BestAction:        p.BestAction,
+		BestActionStable:  int32(p.BestActionStable),
+		StoreLatencyUs:    p.StoreLatency.Microseconds(),
+		Done:              p.Done,
+		StopReason:        p.StopReason.String(),
+	}
+}
@@ -0,0 +1,82 @@
+package rpc
+
+import (
+	"context"
+	tree "github.com/danielsussa/tmp_tree"
+	"github.com/danielsussa/tmp_tree/internal/treetest"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"testing"
+	"time"
+)
+
+func dial(t *testing.T, config Config) *Client {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	RegisterStateTreeServer(server, NewServer(tree.New().Seed(1), GameCodec(treetest.CountdownGame), config))
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return NewClient(conn, GameCodec(treetest.CountdownGame))
+}
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	client := dial(t, Config{MaxIterations: 100})
+
+	_, err := client.GetNode(ctx, "2")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	result, err := client.Search(ctx, &treetest.Countdown{N: 2}, 1000, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "2", result.BestAction)
+	assert.Equal(t, int32(100), result.Playouts)
+	assert.Equal(t, "iterations", result.StopReason)
+	assert.Len(t, result.Actions, 2)
+
+	node, err := client.GetNode(ctx, "2")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", node.Actions[0].Id)
+
+	reports := make([]*Progress, 0)
+	err = client.Train(ctx, &treetest.Countdown{N: 4}, &TrainRequest{MaxIterations: 50, ReportEvery: 20}, func(p *Progress) {
+		reports = append(reports, p)
+	})
+	assert.NoError(t, err)
+	assert.Len(t, reports, 3)
+	assert.Equal(t, int32(50), reports[2].Playouts)
+	assert.True(t, reports[2].Done)
+}
+
+func TestServiceCancel(t *testing.T) {
+	client := dial(t, Config{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := client.Train(ctx, &treetest.Countdown{N: 4}, &TrainRequest{MaxIterations: 1 << 30, ReportEvery: 10}, func(p *Progress) {
+		cancel()
+	})
+	assert.Equal(t, codes.Canceled, status.Code(err))
+
+	// the abandoned training stops and releases the tree
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	result, err := client.Search(ctx, &treetest.Countdown{N: 4}, 10, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(10), result.Playouts)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.Search(cancelled, &treetest.Countdown{N: 4}, 1<<30, 0)
+	assert.Equal(t, codes.Canceled, status.Code(err))
+}
@@ -0,0 +1,207 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package rpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// StateTreeClient is the client API for StateTree service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type StateTreeClient interface {
+	// Search train from the state and rank its actions
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	// GetNode statistics of the node stored for a state ID
+	GetNode(ctx context.Context, in *GetNodeRequest, opts ...grpc.CallOption) (*Node, error)
+	// Train from the state, streaming progress reports until it stops
+	Train(ctx context.Context, in *TrainRequest, opts ...grpc.CallOption) (StateTree_TrainClient, error)
+}
+
+type stateTreeClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewStateTreeClient(cc grpc.ClientConnInterface) StateTreeClient {
+	return &stateTreeClient{cc}
+}
+
+func (c *stateTreeClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, "/sktree.StateTree/Search", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stateTreeClient) GetNode(ctx context.Context, in *GetNodeRequest, opts ...grpc.CallOption) (*Node, error) {
+	out := new(Node)
+	err := c.cc.Invoke(ctx, "/sktree.StateTree/GetNode", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stateTreeClient) Train(ctx context.Context, in *TrainRequest, opts ...grpc.CallOption) (StateTree_TrainClient, error) {
+	stream, err := c.cc.NewStream(ctx, &StateTree_ServiceDesc.Streams[0], "/sktree.StateTree/Train", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &stateTreeTrainClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type StateTree_TrainClient interface {
+	Recv() (*Progress, error)
+	grpc.ClientStream
+}
+
+type stateTreeTrainClient struct {
+	grpc.ClientStream
+}
+
+func (x *stateTreeTrainClient) Recv() (*Progress, error) {
+	m := new(Progress)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// StateTreeServer is the server API for StateTree service.
+// All implementations must embed UnimplementedStateTreeServer
+// for forward compatibility
+type StateTreeServer interface {
+	// Search train from the state and rank its actions
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	// GetNode statistics of the node stored for a state ID
+	GetNode(context.Context, *GetNodeRequest) (*Node, error)
+	// Train from the state, streaming progress reports until it stops
+	Train(*TrainRequest, StateTree_TrainServer) error
+	mustEmbedUnimplementedStateTreeServer()
+}
+
+// UnimplementedStateTreeServer must be embedded to have forward compatible implementations.
+type UnimplementedStateTreeServer struct {
+}
+
+func (UnimplementedStateTreeServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedStateTreeServer) GetNode(context.Context, *GetNodeRequest) (*Node, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNode not implemented")
+}
+func (UnimplementedStateTreeServer) Train(*TrainRequest, StateTree_TrainServer) error {
+	return status.Errorf(codes.Unimplemented, "method Train not implemented")
+}
+func (UnimplementedStateTreeServer) mustEmbedUnimplementedStateTreeServer() {}
+
+// UnsafeStateTreeServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to StateTreeServer will
+// result in compilation errors.
+type UnsafeStateTreeServer interface {
+	mustEmbedUnimplementedStateTreeServer()
+}
+
+func RegisterStateTreeServer(s grpc.ServiceRegistrar, srv StateTreeServer) {
+	s.RegisterService(&StateTree_ServiceDesc, srv)
+}
+
+func _StateTree_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StateTreeServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sktree.StateTree/Search",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StateTreeServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StateTree_GetNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StateTreeServer).GetNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sktree.StateTree/GetNode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StateTreeServer).GetNode(ctx, req.(*GetNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StateTree_Train_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TrainRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StateTreeServer).Train(m, &stateTreeTrainServer{stream})
+}
+
+type StateTree_TrainServer interface {
+	Send(*Progress) error
+	grpc.ServerStream
+}
+
+type stateTreeTrainServer struct {
+	grpc.ServerStream
+}
+
+func (x *stateTreeTrainServer) Send(m *Progress) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// StateTree_ServiceDesc is the grpc.ServiceDesc for StateTree service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var StateTree_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "sktree.StateTree",
+	HandlerType: (*StateTreeServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Search",
+			Handler:    _StateTree_Search_Handler,
+		},
+		{
+			MethodName: "GetNode",
+			Handler:    _StateTree_GetNode_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Train",
+			Handler:       _StateTree_Train_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "sktree.proto",
+}
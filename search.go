@@ -0,0 +1,164 @@
+package tree
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+type ActionStats struct {
+	ID       string
+	NVisited int
+	Mean     float64
+	// Lower and Upper bounds of the mean, using the UCB exploration term
+	// as the half width
+	Lower float64
+	Upper float64
+	Proof Proof
+	Prior float64
+	// MaxScore best observed return, only set by single player searches
+	MaxScore float64
+}
+
+type SearchResult struct {
+	BestAction string
+	// Actions ordered from the most visited to the least visited
+	Actions []ActionStats
+	// PrincipalVariation most visited line of play, starting with BestAction
+	PrincipalVariation []string
+	Playouts           int
+	Elapsed            time.Duration
+	// NodesVisited number of distinct nodes visited during the search
+	NodesVisited int
+	// BestTrajectory best simulation found, only set by single player searches
+	BestTrajectory []string
+	StopReason     StopReason
+}
+
+// Search train from the state and report what was found, without playing
+// the best action
+func (st *StateTree) Search(state State, config StateTreeConfig) SearchResult {
+	start := time.Now()
+	playouts, visited, reason := st.train(state, config)
+
+	result := SearchResult{
+		Playouts:     playouts,
+		NodesVisited: len(visited),
+		StopReason:   reason,
+	}
+	node, _ := st.getOrCreateNode(state, st.retained, StateTreeConfig{})
+	if len(node.Actions) > 0 {
+		result.Actions = actionStats(node)
+		result.BestAction = BestAction(result.Actions)
+		result.PrincipalVariation = st.PrincipalVariation(state)
+	}
+	if config.SinglePlayer {
+		if action := maxScoreAction(node); action != nil {
+			result.BestAction = action.ID
+		}
+		if trajectory, ok := st.BestTrajectory(state); ok {
+			result.BestTrajectory = trajectory.Actions
+		}
+	}
+	result.Elapsed = time.Since(start)
+	return result
+}
+
+// NodeStats statistics of the actions of the node stored for the state ID,
+// ordered from the most visited to the least visited
+func (st *StateTree) NodeStats(id string) ([]ActionStats, bool) {
+	node, ok := st.findNode(id, st.retained)
+	if !ok {
+		return nil, false
+	}
+	return actionStats(node), true
+}
+
+func actionStats(node *Node) []ActionStats {
+	nodeVisited := node.nVisited()
+	stats := make([]ActionStats, 0, len(node.Actions))
+	for _, action := range node.Actions {
+		actionStats := ActionStats{
+			ID:       action.ID,
+			NVisited: action.NVisited,
+			Proof:    action.Proof,
+			Prior:    action.Prior,
+			MaxScore: action.MaxScore,
+		}
+		if action.NVisited > 0 {
+			actionStats.Mean = action.Score / float64(action.NVisited)
+			halfWidth := math.Sqrt(2 * math.Log(float64(nodeVisited)) / float64(action.NVisited))
+			actionStats.Lower = actionStats.Mean - halfWidth
+			actionStats.Upper = actionStats.Mean + halfWidth
+		}
+		stats = append(stats, actionStats)
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].NVisited > stats[j].NVisited
+	})
+	return stats
+}
+
+// BestAction is a proven win when there is one, otherwise the most visited
+// action that is not a proven loss. It is the BestAction of Search, servers
+// answering from NodeStats use it to pick the same action.
+func BestAction(stats []ActionStats) string {
+	best := -1
+	for i, action := range stats {
+		if action.Proof == ProvenWin {
+			return action.ID
+		}
+		if best == -1 || stats[best].Proof == ProvenLoss && action.Proof != ProvenLoss {
+			best = i
+			continue
+		}
+		if action.Proof != ProvenLoss && action.NVisited > stats[best].NVisited {
+			best = i
+		}
+	}
+	if best == -1 {
+		return ""
+	}
+	return stats[best].ID
+}
+
+// bestAction of the node, see BestAction
+func bestAction(node *Node) *Action {
+	id := BestAction(actionStats(node))
+	for _, action := range node.Actions {
+		if action.ID == id {
+			return action
+		}
+	}
+	return nil
+}
+
+// PrincipalVariation follow the best action from node to node, replaying
+// it on a copy of the state until the game ends or the line was never
+// visited
+func (st *StateTree) PrincipalVariation(s State) []string {
+	state := s.Copy()
+	line := make([]string, 0)
+	seen := make(map[string]bool, 0)
+	for depth := 0; ; depth++ {
+		id := state.ID()
+		if seen[id] {
+			break
+		}
+		seen[id] = true
+
+		node, newNode := st.getOrCreateNode(state, st.retained, StateTreeConfig{})
+		if newNode || len(node.Actions) == 0 || node.nVisited() == 0 {
+			break
+		}
+		action := bestAction(node)
+		line = append(line, action.ID)
+
+		state.PlayAction(action.ID)
+		st.playSideEffects(state)
+		if state.TurnResult(TurnRequest{Depth: depth + 1, Rand: st.rand}).EndGame {
+			break
+		}
+	}
+	return line
+}
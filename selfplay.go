@@ -0,0 +1,159 @@
+package tree
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+type SelfPlayConfig struct {
+	// Train search run before every move
+	Train StateTreeConfig
+	// TemperatureMoves number of opening moves sampled from the visit
+	// distribution, later moves take the most visited action
+	TemperatureMoves int
+	// Temperature applied to the visits while sampling, defaults to 1
+	Temperature float64
+}
+
+// TrainingExample is one move of a self-play game: the visit distribution
+// at the root and the final outcome of the game
+type TrainingExample struct {
+	StateID string             `json:"state"`
+	Policy  map[string]float64 `json:"policy"`
+	Value   float64            `json:"value"`
+}
+
+// SelfPlay play a full game from s, searching before every move, and return
+// one training example per move. s itself is not modified.
+func (st *StateTree) SelfPlay(s State, config SelfPlayConfig) []TrainingExample {
+	state := s.Copy()
+	examples := make([]TrainingExample, 0)
+
+	for move := 0; ; move++ {
+		st.Train(state, config.Train)
+
+		node, _ := st.getOrCreateNode(state, nil, StateTreeConfig{})
+		if len(node.Actions) == 0 {
+			break
+		}
+		policy := visitPolicy(node)
+		examples = append(examples, TrainingExample{
+			StateID: node.id,
+			Policy:  policy,
+		})
+
+		temperature := 0.0
+		if move < config.TemperatureMoves {
+			temperature = config.temperature()
+		}
+		state.PlayAction(sampleAction(node, temperature, st.rand))
+		st.playSideEffects(state)
+
+		if state.TurnResult(TurnRequest{Depth: move + 1, Rand: st.rand}).EndGame {
+			break
+		}
+	}
+
+	value := float64(state.GameResult().Score)
+	for i := range examples {
+		examples[i].Value = value
+	}
+	return examples
+}
+
+func (config SelfPlayConfig) temperature() float64 {
+	if config.Temperature <= 0 {
+		return 1
+	}
+	return config.Temperature
+}
+
+func visitPolicy(node *Node) map[string]float64 {
+	total := node.nVisited()
+	policy := make(map[string]float64, len(node.Actions))
+	for _, action := range node.Actions {
+		if total == 0 {
+			policy[action.ID] = 1 / float64(len(node.Actions))
+			continue
+		}
+		policy[action.ID] = float64(action.NVisited) / float64(total)
+	}
+	return policy
+}
+
+// sampleAction pick an action proportionally to NVisited^(1/temperature),
+// a zero temperature always pick the most visited one
+func sampleAction(node *Node, temperature float64, r *rand.Rand) string {
+	actions := make([]*Action, len(node.Actions))
+	copy(actions, node.Actions)
+	sort.SliceStable(actions, func(i, j int) bool {
+		return actions[i].NVisited > actions[j].NVisited
+	})
+	if temperature == 0 || actions[0].NVisited == 0 {
+		return actions[0].ID
+	}
+
+	weights := make([]float64, len(actions))
+	total := 0.0
+	for i, action := range actions {
+		weights[i] = math.Pow(float64(action.NVisited), 1/temperature)
+		total += weights[i]
+	}
+	x := r.Float64() * total
+	for i, weight := range weights {
+		x -= weight
+		if x < 0 {
+			return actions[i].ID
+		}
+	}
+	return actions[len(actions)-1].ID
+}
+
+// WriteExamples write every example as a JSON record prefixed by its
+// length as an uvarint
+func WriteExamples(w io.Writer, examples []TrainingExample) error {
+	buf := make([]byte, binary.MaxVarintLen64)
+	for _, example := range examples {
+		b, err := json.Marshal(example)
+		if err != nil {
+			return err
+		}
+		n := binary.PutUvarint(buf, uint64(len(b)))
+		if _, err := w.Write(buf[:n]); err != nil {
+			return err
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadExamples read back the records written by WriteExamples
+func ReadExamples(r io.Reader) ([]TrainingExample, error) {
+	reader := bufio.NewReader(r)
+	examples := make([]TrainingExample, 0)
+	for {
+		size, err := binary.ReadUvarint(reader)
+		if err == io.EOF {
+			return examples, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		b := make([]byte, size)
+		if _, err := io.ReadFull(reader, b); err != nil {
+			return nil, err
+		}
+		example := TrainingExample{}
+		if err := json.Unmarshal(b, &example); err != nil {
+			return nil, err
+		}
+		examples = append(examples, example)
+	}
+}
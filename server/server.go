@@ -0,0 +1,205 @@
+// Package server expose a StateTree over HTTP, so game backends can ask for
+// moves without embedding the training code.
+//
+// Endpoints:
+//
+//	POST /moves          body is a serialized state, ranked moves of its node
+//	POST /search         body is a serialized state, train then rank its moves,
+//	                     bounded by the iterations and timeout_ms query values
+//	GET  /nodes/{id}     statistics of the node stored for the state ID
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	tree "github.com/danielsussa/tmp_tree"
+	"github.com/danielsussa/tmp_tree/internal/service"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Decoder turn the body of a request into the state it describes, the
+// Decode of a registered tree.Game can be used
+type Decoder func(data []byte) (tree.State, error)
+
+type Config = service.Config
+
+// maxBodyBytes bound the serialized state of a request
+const maxBodyBytes = 1 << 20
+
+type Server struct {
+	// StateTree is not safe for concurrent use
+	mu     sync.Mutex
+	tree   *tree.StateTree
+	decode Decoder
+	config Config
+	mux    *http.ServeMux
+}
+
+type Move struct {
+	Action   string  `json:"action"`
+	Visits   int     `json:"visits"`
+	Mean     float64 `json:"mean"`
+	Lower    float64 `json:"lower"`
+	Upper    float64 `json:"upper"`
+	Proof    string  `json:"proof"`
+	Prior    float64 `json:"prior"`
+	MaxScore float64 `json:"max_score,omitempty"`
+}
+
+type MovesResponse struct {
+	State      string `json:"state"`
+	BestAction string `json:"best_action,omitempty"`
+	Moves      []Move `json:"moves"`
+	// set by /search only
+	PrincipalVariation []string `json:"principal_variation,omitempty"`
+	Playouts           int      `json:"playouts,omitempty"`
+	ElapsedMs          int64    `json:"elapsed_ms,omitempty"`
+	StopReason         string   `json:"stop_reason,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func New(st *tree.StateTree, decode Decoder, config Config) *Server {
+	s := &Server{tree: st, decode: decode, config: config, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/moves", s.moves)
+	s.mux.HandleFunc("/search", s.search)
+	s.mux.HandleFunc("/nodes/", s.nodes)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) moves(w http.ResponseWriter, r *http.Request) {
+	state, ok := s.readState(w, r)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	stats, found := s.tree.NodeStats(state.ID())
+	s.mu.Unlock()
+	if !found {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("state %s was never explored", state.ID()))
+		return
+	}
+	writeJSON(w, http.StatusOK, movesResponse(state.ID(), stats))
+}
+
+func (s *Server) search(w http.ResponseWriter, r *http.Request) {
+	config, err := s.searchConfig(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	state, ok := s.readState(w, r)
+	if !ok {
+		return
+	}
+	// a client that goes away stops the search
+	config.Context = r.Context()
+	s.mu.Lock()
+	result := s.tree.Search(state, config)
+	s.mu.Unlock()
+
+	response := movesResponse(state.ID(), result.Actions)
+	response.PrincipalVariation = result.PrincipalVariation
+	response.Playouts = result.Playouts
+	response.ElapsedMs = result.Elapsed.Milliseconds()
+	response.StopReason = result.StopReason.String()
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) nodes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "use GET")
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/nodes/")
+	s.mu.Lock()
+	stats, found := s.tree.NodeStats(id)
+	s.mu.Unlock()
+	if !found {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("node %s not found", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, movesResponse(id, stats))
+}
+
+// searchConfig parse the iterations and timeout_ms query values, see
+// service.Config.Request
+func (s *Server) searchConfig(r *http.Request) (tree.StateTreeConfig, error) {
+	iterations := 0
+	if v := r.URL.Query().Get("iterations"); v != "" {
+		var err error
+		if iterations, err = strconv.Atoi(v); err != nil || iterations < 0 {
+			return tree.StateTreeConfig{}, fmt.Errorf("invalid iterations %q", v)
+		}
+	}
+	var timeout time.Duration
+	if v := r.URL.Query().Get("timeout_ms"); v != "" {
+		ms, err := strconv.Atoi(v)
+		if err != nil || ms <= 0 {
+			return tree.StateTreeConfig{}, fmt.Errorf("invalid timeout_ms %q", v)
+		}
+		timeout = time.Duration(ms) * time.Millisecond
+	}
+	return s.config.Request(iterations, timeout), nil
+}
+
+func (s *Server) readState(w http.ResponseWriter, r *http.Request) (tree.State, bool) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "use POST")
+		return nil, false
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil && len(body) == maxBodyBytes {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("state larger than %d bytes", maxBodyBytes))
+		return nil, false
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	state, err := s.decode(body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("decode state: %s", err))
+		return nil, false
+	}
+	return state, true
+}
+
+func movesResponse(id string, stats []tree.ActionStats) MovesResponse {
+	response := MovesResponse{State: id, Moves: make([]Move, 0, len(stats))}
+	for _, action := range stats {
+		response.Moves = append(response.Moves, Move{
+			Action:   action.ID,
+			Visits:   action.NVisited,
+			Mean:     action.Mean,
+			Lower:    action.Lower,
+			Upper:    action.Upper,
+			Proof:    action.Proof.String(),
+			Prior:    action.Prior,
+			MaxScore: action.MaxScore,
+		})
+	}
+	response.BestAction = tree.BestAction(stats)
+	return response
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, errorResponse{Error: msg})
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	tree "github.com/danielsussa/tmp_tree"
+	"github.com/danielsussa/tmp_tree/internal/treetest"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func do(t *testing.T, s *Server, method, url, body string) (int, MovesResponse) {
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, httptest.NewRequest(method, url, strings.NewReader(body)))
+	var response MovesResponse
+	if recorder.Code == http.StatusOK {
+		assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&response))
+	}
+	return recorder.Code, response
+}
+
+func TestServer(t *testing.T) {
+	s := New(tree.New().Seed(1), treetest.CountdownGame.Decode, Config{MaxIterations: 100})
+
+	code, _ := do(t, s, "POST", "/moves", "2")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, response := do(t, s, "POST", "/search?iterations=1000", "2")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 100, response.Playouts)
+	assert.Equal(t, "2", response.BestAction)
+	assert.Equal(t, "iterations", response.StopReason)
+	assert.Len(t, response.Moves, 2)
+
+	code, response = do(t, s, "POST", "/moves", "2")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "2", response.BestAction)
+	assert.Equal(t, 0, response.Playouts)
+
+	code, response = do(t, s, "GET", "/nodes/2", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "2", response.State)
+	assert.Len(t, response.Moves, 2)
+
+	code, _ = do(t, s, "POST", "/moves", "two")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = do(t, s, "GET", "/search", "2")
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+}
+
+func TestSearchCancelled(t *testing.T) {
+	s := New(tree.New(), treetest.CountdownGame.Decode, Config{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest("POST", "/search?iterations=1000000", strings.NewReader("4")).WithContext(ctx)
+	s.ServeHTTP(recorder, request)
+	var response MovesResponse
+	assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&response))
+	assert.Equal(t, 0, response.Playouts)
+	assert.Equal(t, "cancelled", response.StopReason)
+}
+
+func TestBodyTooLarge(t *testing.T) {
+	s := New(tree.New(), treetest.CountdownGame.Decode, Config{})
+	code, _ := do(t, s, "POST", "/moves", strings.Repeat("1", maxBodyBytes+1))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+}
+
+func TestMovesBestAction(t *testing.T) {
+	s := New(tree.New().Seed(1), treetest.CountdownGame.Decode, Config{})
+	for _, state := range []string{"4", "3", "2"} {
+		_, searched := do(t, s, "POST", "/search?iterations=200", state)
+		_, moves := do(t, s, "POST", "/moves", state)
+		_, nodes := do(t, s, "GET", "/nodes/"+state, "")
+		assert.Equal(t, searched.BestAction, moves.BestAction)
+		assert.Equal(t, searched.BestAction, nodes.BestAction)
+	}
+}
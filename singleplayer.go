@@ -0,0 +1,75 @@
+package tree
+
+import "math"
+
+// Trajectory is the sequence of actions of the simulation with the best
+// return from a root, chance side effects may not replay the same way
+type Trajectory struct {
+	Actions []string
+	Score   float64
+}
+
+// varianceTerm is the SP-MCTS term sqrt((sum(x^2) - n * mean^2 + D) / n)
+func varianceTerm(action *Action, d float64) float64 {
+	if action.NVisited == 0 {
+		return math.Sqrt(d)
+	}
+	n := float64(action.NVisited)
+	mean := action.Score / n
+	variance := (action.SquaredScore - n*mean*mean + d) / n
+	if variance < 0 {
+		return 0
+	}
+	return math.Sqrt(variance)
+}
+
+func (st *StateTree) updateSinglePlayer(sim *simulation, returns []float64) {
+	seen := make(map[*Action]bool, len(sim.actionList))
+	for i, action := range sim.actionList {
+		if seen[action] {
+			continue
+		}
+		seen[action] = true
+		action.SquaredScore += returns[i] * returns[i]
+		if !action.hasMax || returns[i] > action.MaxScore {
+			action.MaxScore = returns[i]
+			action.hasMax = true
+		}
+	}
+	if len(sim.actionList) == 0 {
+		return
+	}
+
+	rootID := sim.nodeList[0].id
+	if best, ok := st.trajectories[rootID]; ok && best.Score >= returns[0] {
+		return
+	}
+	actions := make([]string, 0, len(sim.actionList))
+	for _, action := range sim.actionList {
+		actions = append(actions, action.ID)
+	}
+	st.trajectories[rootID] = Trajectory{Actions: actions, Score: returns[0]}
+}
+
+// BestTrajectory return the best simulation found by single player
+// searches starting from the state
+func (st *StateTree) BestTrajectory(s State) (Trajectory, bool) {
+	trajectory, ok := st.trajectories[s.ID()]
+	return trajectory, ok
+}
+
+// maxScoreAction is the action with the best observed outcome, the most
+// visited one among equals
+func maxScoreAction(node *Node) *Action {
+	var best *Action
+	for _, action := range node.Actions {
+		if !action.hasMax {
+			continue
+		}
+		if best == nil || action.MaxScore > best.MaxScore ||
+			action.MaxScore == best.MaxScore && action.NVisited > best.NVisited {
+			best = action
+		}
+	}
+	return best
+}
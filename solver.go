@@ -0,0 +1,178 @@
+package tree
+
+// Proof is the game theoretical value of an action once the solver has
+// proven it, taken from the sign of GameResult.Score
+type Proof int
+
+const (
+	Unproven Proof = iota
+	ProvenLoss
+	ProvenDraw
+	ProvenWin
+)
+
+func (p Proof) String() string {
+	switch p {
+	case ProvenLoss:
+		return "loss"
+	case ProvenDraw:
+		return "draw"
+	case ProvenWin:
+		return "win"
+	}
+	return "unproven"
+}
+
+// SideEffectsState can be implemented by states whose side effects have a
+// finite set of outcomes (e.g. every reply of the opponent). The solver
+// treats them as adversarial, without it only actions that end the game
+// before the side effects are played can be proven.
+type SideEffectsState interface {
+	// SideEffectOutcomes return every state PlaySideEffects can lead to,
+	// an empty slice means the side effects leave the state unchanged
+	SideEffectOutcomes() []State
+}
+
+type solverStep struct {
+	action *Action
+	// state before action was played
+	state State
+}
+
+func proofFromResult(result GameResult) Proof {
+	if result.Score > 0 {
+		return ProvenWin
+	}
+	if result.Score < 0 {
+		return ProvenLoss
+	}
+	return ProvenDraw
+}
+
+// proof of the node: win if any action is a proven win, otherwise
+// the best proof once every action is proven
+func (n *Node) proof() Proof {
+	if len(n.Actions) == 0 {
+		return Unproven
+	}
+	best := ProvenLoss
+	for _, action := range n.Actions {
+		if action.Proof == ProvenWin {
+			return ProvenWin
+		}
+		if action.Proof == Unproven {
+			best = Unproven
+			continue
+		}
+		if best != Unproven && action.Proof > best {
+			best = action.Proof
+		}
+	}
+	return best
+}
+
+// provenAction return the action to play when the node no longer needs to
+// be sampled: a proven win wherever it is listed, otherwise the best proof
+// once every action is proven, nil while there are unproven actions left.
+// Only a win settles a partial node, see partial.
+func (n *Node) provenAction(partial bool) *Action {
+	for _, action := range n.Actions {
+		if action.Proof == ProvenWin {
+			return action
+		}
+	}
+	if partial {
+		return nil
+	}
+	var best *Action
+	for _, action := range n.Actions {
+		if action.Proof == Unproven {
+			return nil
+		}
+		if best == nil || action.Proof > best.Proof {
+			best = action
+		}
+	}
+	return best
+}
+
+func (st *StateTree) solve(steps []solverStep, nodeMap map[string]*Node, config StateTreeConfig) {
+	for i := len(steps) - 1; i >= 0; i-- {
+		step := steps[i]
+		if step.action.Proof != Unproven {
+			continue
+		}
+		step.action.Proof = st.actionProof(step.state, step.action, nodeMap, config)
+	}
+}
+
+func (st *StateTree) actionProof(s State, action *Action, nodeMap map[string]*Node, config StateTreeConfig) Proof {
+	state := s.Copy()
+	state.PlayAction(action.ID)
+	if state.TurnResult(TurnRequest{Depth: 1, Rand: st.rand}).EndGame {
+		return proofFromResult(state.GameResult())
+	}
+
+	sideEffects, ok := state.(SideEffectsState)
+	if !ok {
+		return Unproven
+	}
+	outcomes := sideEffects.SideEffectOutcomes()
+	if len(outcomes) == 0 {
+		outcomes = []State{state}
+	}
+
+	proof := ProvenWin
+	for _, outcome := range outcomes {
+		outcomeProof := st.stateProof(outcome, nodeMap, config)
+		if outcomeProof == ProvenLoss {
+			return ProvenLoss
+		}
+		if outcomeProof == Unproven || proof == Unproven {
+			proof = Unproven
+			continue
+		}
+		if outcomeProof < proof {
+			proof = outcomeProof
+		}
+	}
+	return proof
+}
+
+func (st *StateTree) stateProof(state State, nodeMap map[string]*Node, config StateTreeConfig) Proof {
+	if state.TurnResult(TurnRequest{Rand: st.rand}).EndGame {
+		return proofFromResult(state.GameResult())
+	}
+	node, _ := st.getOrCreateNode(state, nodeMap, config)
+	return nodeProof(node, state, config)
+}
+
+// nodeProof is the proof of the node, a partial node can only be proven by
+// one of its wins
+func nodeProof(node *Node, state State, config StateTreeConfig) Proof {
+	proof := node.proof()
+	if proof != ProvenWin && partial(node, state, config) {
+		return Unproven
+	}
+	return proof
+}
+
+// partial report whether actions of the state may still be missing from
+// the node. Without widening in the config, only a node holding fewer
+// actions than the state is known to be partial.
+func partial(node *Node, state State, config StateTreeConfig) bool {
+	if config.widening() {
+		return !isFullyWidened(node, state)
+	}
+	return len(node.Actions) < len(state.PossibleActions())
+}
+
+// Proof return what the solver could prove about the given state so far
+func (st *StateTree) Proof(s State) Proof {
+	return st.proof(s, StateTreeConfig{})
+}
+
+func (st *StateTree) proof(s State, config StateTreeConfig) Proof {
+	node, _ := st.getOrCreateNode(s, st.retained, config)
+	return nodeProof(node, s, config)
+}
@@ -0,0 +1,159 @@
+package tree
+
+import "math"
+
+// DefaultStopTolerance largest change of the visit share and of the lower
+// bound of the best root action for StopWindow to consider it converged
+const DefaultStopTolerance = 0.01
+
+// StopReason why training ended
+type StopReason int
+
+const (
+	// StopIterations MaxIterations playouts were run
+	StopIterations StopReason = iota
+	// StopTimeout MaxTimeout elapsed
+	StopTimeout
+	// StopProven the solver proved the root
+	StopProven
+	// StopUnreachable no other action can overtake the most visited one
+	// within the remaining iterations, see StopWhenUnreachable
+	StopUnreachable
+	// StopConverged the best action was stable over StopWindow playouts
+	StopConverged
+	// StopCancelled StateTreeConfig.Context is done
+	StopCancelled
+)
+
+func (r StopReason) String() string {
+	switch r {
+	case StopIterations:
+		return "iterations"
+	case StopTimeout:
+		return "timeout"
+	case StopProven:
+		return "proven"
+	case StopUnreachable:
+		return "unreachable"
+	case StopConverged:
+		return "converged"
+	case StopCancelled:
+		return "cancelled"
+	}
+	return "unknown"
+}
+
+func (config StateTreeConfig) stopTolerance() float64 {
+	if config.StopTolerance <= 0 {
+		return DefaultStopTolerance
+	}
+	return config.StopTolerance
+}
+
+type rootSnapshot struct {
+	playouts int
+	best     string
+	// share of the root visits going to the best action
+	share float64
+	lower float64
+}
+
+// convergence keep the snapshots of the root taken during the last
+// StopWindow playouts
+type convergence struct {
+	window    int
+	tolerance float64
+	history   []rootSnapshot
+	// playouts of the call started by each root action
+	plays map[string]int
+}
+
+func newConvergence(config StateTreeConfig) *convergence {
+	return &convergence{window: config.StopWindow, tolerance: config.stopTolerance(), plays: make(map[string]int, 0)}
+}
+
+// maxPlayoutGain bound the visits a root action can gain in one playout:
+// every action on the path gains a visit per step and MaxDepth bounds the
+// steps, BackupAllParents can add one more through another parent. There
+// is no bound without MaxDepth, the playouts started by each action are
+// compared instead, see unreachable.
+func (config StateTreeConfig) maxPlayoutGain() (int, bool) {
+	if config.MaxDepth <= 0 {
+		return 0, false
+	}
+	if config.Backup == BackupAllParents {
+		return config.MaxDepth + 1, true
+	}
+	return config.MaxDepth, true
+}
+
+// check the root after a batch of playouts, remaining is the number of
+// playouts left before MaxIterations
+func (c *convergence) check(root *Node, sims []*simulation, playouts, remaining int, config StateTreeConfig) (StopReason, bool) {
+	for _, sim := range sims {
+		if len(sim.actionList) > 0 {
+			c.plays[sim.actionList[0].ID]++
+		}
+	}
+	if root == nil || len(root.Actions) == 0 {
+		return StopIterations, false
+	}
+	stats := actionStats(root)
+
+	if config.StopWhenUnreachable && c.unreachable(stats, remaining, config) {
+		return StopUnreachable, true
+	}
+
+	if c.window > 0 {
+		snapshot := rootSnapshot{
+			playouts: playouts,
+			best:     stats[0].ID,
+			share:    float64(stats[0].NVisited) / float64(root.nVisited()),
+			lower:    stats[0].Lower,
+		}
+		if c.converged(snapshot) {
+			return StopConverged, true
+		}
+	}
+	return StopIterations, false
+}
+
+// unreachable report whether the most visited root action keeps the lead
+// over the remaining playouts. With MaxDepth its visits are compared,
+// otherwise it also has to lead the playouts of the call, where a playout
+// adds one to a single action.
+func (c *convergence) unreachable(stats []ActionStats, remaining int, config StateTreeConfig) bool {
+	if gain, ok := config.maxPlayoutGain(); ok {
+		second := 0
+		if len(stats) > 1 {
+			second = stats[1].NVisited
+		}
+		return stats[0].NVisited-second > remaining*gain
+	}
+	best := stats[0].ID
+	second := 0
+	for id, plays := range c.plays {
+		if id != best && plays > second {
+			second = plays
+		}
+	}
+	return c.plays[best]-second > remaining
+}
+
+func (c *convergence) converged(snapshot rootSnapshot) bool {
+	c.history = append(c.history, snapshot)
+	for len(c.history) > 1 && snapshot.playouts-c.history[1].playouts >= c.window {
+		c.history = c.history[1:]
+	}
+	if snapshot.playouts-c.history[0].playouts < c.window {
+		return false
+	}
+	for _, h := range c.history {
+		if h.best != snapshot.best ||
+			math.Abs(h.share-snapshot.share) > c.tolerance ||
+			math.Abs(h.lower-snapshot.lower) > c.tolerance {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+package tree
+
+import (
+	"sort"
+	"strings"
+)
+
+// IterableDatabase is a Database whose records can be listed, needed to
+// export, merge and summarize a store
+type IterableDatabase interface {
+	Database
+	// Each call f for every record until f returns an error
+	Each(f func(key, val string) error) error
+}
+
+// Each list the records ordered by key
+func (dmp DefaultMemoryDB) Each(f func(key, val string) error) error {
+	keys := make([]string, 0, len(dmp.nodeMap))
+	for key := range dmp.nodeMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := f(key, dmp.nodeMap[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MergeStore add the records of src to dst. Nodes found in both get the
+// visits and scores of their actions summed, so stores trained apart can be
+// combined.
+func MergeStore(dst Database, src IterableDatabase) (merged int, err error) {
+	err = src.Each(func(key, val string) error {
+		current, ok := dst.Find(key)
+		if ok {
+			val = mergeRecords(key, current, val)
+		}
+		merged++
+		return dst.Add(key, val)
+	})
+	return merged, err
+}
+
+func mergeRecords(key, dst, src string) string {
+	if key == boundsKey {
+		if union := unionBounds(parseBounds(dst), parseBounds(src)); union != nil {
+			return union.toDB()
+		}
+		return dst
+	}
+	if strings.HasPrefix(key, "#") {
+		return dst
+	}
+	node, other := parseToNode(key, dst), parseToNode(key, src)
+	for _, action := range other.Actions {
+		if existing := node.action(action.ID); existing != nil {
+			existing.merge(action)
+			continue
+		}
+		node.Actions = append(node.Actions, action)
+	}
+	node.bounds = unionBounds(node.bounds, other.bounds)
+	if node.priors == nil {
+		node.priors = other.priors
+	}
+	return node.toDB()
+}
+
+func (a *Action) merge(other *Action) {
+	a.NVisited += other.NVisited
+	a.Score += other.Score
+	a.AMAFNVisited += other.AMAFNVisited
+	a.AMAFScore += other.AMAFScore
+	if a.Proof == Unproven {
+		a.Proof = other.Proof
+	}
+	if a.Prior == 0 {
+		a.Prior = other.Prior
+	}
+	if other.hasMax {
+		if !a.hasMax || other.MaxScore > a.MaxScore {
+			a.MaxScore = other.MaxScore
+		}
+		a.SquaredScore += other.SquaredScore
+		a.hasMax = true
+	}
+}
+
+func unionBounds(a, b *bounds) *bounds {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	union := &bounds{min: a.min, max: a.max}
+	union.update(b.min)
+	union.update(b.max)
+	return union
+}
+
+type StoreStats struct {
+	Nodes   int
+	Actions int
+	// Visits sum of the visits of every action
+	Visits int
+	// Proven actions the solver proved
+	Proven int
+	// Bytes size of the keys and values
+	Bytes int
+}
+
+func ReadStoreStats(db IterableDatabase) (StoreStats, error) {
+	stats := StoreStats{}
+	err := db.Each(func(key, val string) error {
+		stats.Bytes += len(key) + len(val)
+		if strings.HasPrefix(key, "#") {
+			return nil
+		}
+		stats.Nodes++
+		for _, action := range parseToNode(key, val).Actions {
+			stats.Actions++
+			stats.Visits += action.NVisited
+			if action.Proof != Unproven {
+				stats.Proven++
+			}
+		}
+		return nil
+	})
+	return stats, err
+}
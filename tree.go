@@ -1,14 +1,17 @@
 package tree
 
 import (
+	"context"
 	"crypto/sha256"
 	"crypto/sha512"
 	"encoding/hex"
 	"fmt"
 	"math"
+	"math/rand"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -21,6 +24,10 @@ type DefaultMemoryDB struct {
 	nodeMap map[string]string
 }
 
+func NewMemoryDB() DefaultMemoryDB {
+	return DefaultMemoryDB{nodeMap: map[string]string{}}
+}
+
 func (dmp DefaultMemoryDB) Find(key string) (string, bool) {
 	if node, ok := dmp.nodeMap[key]; ok {
 		return node, true
@@ -34,11 +41,29 @@ func (dmp DefaultMemoryDB) Add(key, val string) error {
 }
 
 type StateTree struct {
-	debugState   func(node NodeDebug, debug Debug)
-	debugActions func(actions []*Action, selected *Action)
-	controller   func(req ControllerRequest) ControllerResponse
-	stats        *rootStats
-	db           Database
+	controller func(req ControllerRequest) ControllerResponse
+	evaluator  Evaluator
+	stats      *rootStats
+	db         Database
+	// nodes kept in memory between calls when StateTreeConfig.ReuseTree is set
+	retained map[string]*Node
+	dag      *dag
+	// best trajectory found by single player searches, by root state ID
+	trajectories map[string]Trajectory
+	// returns observed by the whole tree, see NormalizeGlobal
+	bounds   *bounds
+	rand     *rand.Rand
+	reporter Reporter
+	metrics  *Metrics
+	// stop feeding metrics, see SetMetrics
+	unsubscribeMetrics func()
+	// subscribers of the event stream, see Subscribe
+	subMu       sync.Mutex
+	subscribers []*subscriber
+	lastSubID   int
+	// unsubscribe the callbacks set by DebugState and DebugAction
+	debugState  func()
+	debugAction func()
 }
 
 type rootStats struct {
@@ -48,16 +73,77 @@ type rootStats struct {
 type Node struct {
 	Actions []*Action
 	id      string
+	// IDs of the nodes reached from this one, only kept in memory
+	children map[string]bool
+	// returns observed from this node, see NormalizeNode
+	bounds *bounds
+	// priors of the evaluator for actions not added yet by progressive
+	// widening
+	priors map[string]float64
 }
 
 func (n Node) toDB() string {
 	var b strings.Builder
+	if n.bounds != nil {
+		b.WriteString("#b=" + n.bounds.toDB() + ";")
+	}
+	if pending := n.pendingPriors(); pending != "" {
+		b.WriteString("#p=" + pending + ";")
+	}
 	for _, act := range n.Actions {
-		b.WriteString(fmt.Sprintf("%s;%d;%d;", act.ID, act.NVisited, act.Score))
+		b.WriteString(fmt.Sprintf("%s;%d;%s%s;", act.ID, act.NVisited, formatFloat(act.Score), act.extrasToDB()))
 	}
 	return strings.TrimRight(b.String(), ";")
 }
 
+// extrasToDB encode optional action fields as ",key=value" pairs appended to
+// the score, so stores written before they existed can still be parsed
+func (a Action) extrasToDB() string {
+	var b strings.Builder
+	if a.Proof != Unproven {
+		b.WriteString(fmt.Sprintf(",p=%d", a.Proof))
+	}
+	if a.AMAFNVisited != 0 {
+		b.WriteString(fmt.Sprintf(",an=%d,as=%s", a.AMAFNVisited, formatFloat(a.AMAFScore)))
+	}
+	if a.Prior != 0 {
+		b.WriteString(",pr=" + formatFloat(a.Prior))
+	}
+	if a.hasMax {
+		b.WriteString(",mx=" + formatFloat(a.MaxScore) + ",sq=" + formatFloat(a.SquaredScore))
+	}
+	return b.String()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func (a *Action) parseExtras(extras []string) {
+	for _, extra := range extras {
+		kv := strings.SplitN(extra, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "p":
+			proof, _ := strconv.Atoi(kv[1])
+			a.Proof = Proof(proof)
+		case "an":
+			a.AMAFNVisited, _ = strconv.Atoi(kv[1])
+		case "as":
+			a.AMAFScore, _ = strconv.ParseFloat(kv[1], 64)
+		case "pr":
+			a.Prior, _ = strconv.ParseFloat(kv[1], 64)
+		case "mx":
+			a.MaxScore, _ = strconv.ParseFloat(kv[1], 64)
+			a.hasMax = true
+		case "sq":
+			a.SquaredScore, _ = strconv.ParseFloat(kv[1], 64)
+		}
+	}
+}
+
 type NodeDebug struct {
 	Id    string
 	State State
@@ -65,8 +151,20 @@ type NodeDebug struct {
 
 type Action struct {
 	ID       string
-	Score    int
+	Score    float64
 	NVisited int
+	Proof    Proof
+	// All-Moves-As-First statistics, updated whenever the action is played
+	// anywhere later in a simulation that went through its node
+	AMAFScore    float64
+	AMAFNVisited int
+	// Prior heuristic value of the action, see PriorState
+	Prior float64
+	// MaxScore and SquaredScore are the best return and the sum of the
+	// squared returns, only tracked in single player mode
+	MaxScore     float64
+	SquaredScore float64
+	hasMax       bool
 }
 
 type actionScore struct {
@@ -74,15 +172,40 @@ type actionScore struct {
 	score  float64
 }
 
-func (n *Node) selectAction(stats *rootStats) *Action {
+func (n *Node) selectAction(stats *rootStats, config StateTreeConfig, partial bool) *Action {
+	if action := n.provenAction(partial); action != nil {
+		return action
+	}
 	actionScoreList := make([]actionScore, 0)
+	nodeVisited := n.nVisited()
 
 	for _, action := range n.Actions {
+		if action.Proof != Unproven {
+			continue
+		}
+		score := fSelection(action.Score, action.NVisited, stats.NVisited)
+		if config.Rave {
+			score = raveSelection(action, stats.NVisited, config.raveBeta()(action))
+		}
+		if config.PUCT > 0 {
+			score = puctSelection(action, nodeVisited, config.PUCT)
+		}
+		if config.ProgressiveBias > 0 {
+			score += progressiveBias(action, config.ProgressiveBias)
+		}
+		if config.SinglePlayer {
+			score += varianceTerm(action, config.SinglePlayerD)
+		}
 		actionScoreList = append(actionScoreList, actionScore{
 			action: action,
-			score:  fSelection(float64(action.Score), action.NVisited, stats.NVisited),
+			score:  score,
 		})
 	}
+	// every action of a partial node is proven, playing the best one lets
+	// widening add the missing ones
+	if len(actionScoreList) == 0 {
+		return n.provenAction(false)
+	}
 	sort.SliceStable(actionScoreList, func(i, j int) bool {
 		if actionScoreList[i].score > actionScoreList[j].score {
 			return true
@@ -115,43 +238,77 @@ const (
 func parseToNode(key, val string) *Node {
 	valSpl := strings.Split(val, ";")
 
+	// optional node fields come first, prefixed by #
+	var nodeBounds *bounds
+	var priors map[string]float64
+	for len(valSpl) > 0 && strings.HasPrefix(valSpl[0], "#") {
+		switch {
+		case strings.HasPrefix(valSpl[0], "#b="):
+			nodeBounds = parseBounds(strings.TrimPrefix(valSpl[0], "#b="))
+		case strings.HasPrefix(valSpl[0], "#p="):
+			priors = parsePriors(strings.TrimPrefix(valSpl[0], "#p="))
+		}
+		valSpl = valSpl[1:]
+	}
+
 	actions := make([]*Action, 0)
-	for i := 0; i < len(valSpl); i += 3 {
+	for i := 0; i+2 < len(valSpl); i += 3 {
 		id := valSpl[i]
 		nVisited, _ := strconv.Atoi(valSpl[i+1])
-		score, _ := strconv.Atoi(valSpl[i+2])
-		actions = append(actions, &Action{
+		scoreSpl := strings.Split(valSpl[i+2], ",")
+		score, _ := strconv.ParseFloat(scoreSpl[0], 64)
+		action := &Action{
 			ID:       id,
 			Score:    score,
 			NVisited: nVisited,
-		})
+		}
+		action.parseExtras(scoreSpl[1:])
+		actions = append(actions, action)
 	}
 
 	return &Node{
 		Actions: actions,
 		id:      key,
+		bounds:  nodeBounds,
+		priors:  priors,
 	}
 }
 
-func (st *StateTree) getOrCreateNode(state State, nodeMap map[string]*Node) (*Node, bool) {
-	stateId := state.ID()
+func (st *StateTree) findNode(stateId string, nodeMap map[string]*Node) (*Node, bool) {
 	if nodeMap != nil {
-		if val, ok := nodeMap[stateId]; ok {
-			return val, false
+		val, ok := nodeMap[stateId]
+		if st.metrics != nil {
+			st.metrics.observeCache(ok)
+		}
+		if ok {
+			return val, true
 		}
 	}
 
-	if val, ok := st.db.Find(stateId); ok {
+	if val, ok := st.find(stateId); ok {
 		node := parseToNode(stateId, val)
 		node.id = stateId
+		return node, true
+	}
+	return nil, false
+}
+
+func (st *StateTree) getOrCreateNode(state State, nodeMap map[string]*Node, config StateTreeConfig) (*Node, bool) {
+	stateId := state.ID()
+	if node, ok := st.findNode(stateId, nodeMap); ok {
 		return node, false
 	}
 
 	actionList := make([]*Action, 0)
+	// actions are added by widen as the node gets visited
+	if config.widening() {
+		return &Node{Actions: actionList, id: stateId}, true
+	}
 	for _, action := range state.PossibleActions() {
 		actionList = append(actionList, &Action{
 			ID:    action,
 			Score: 0,
+			Prior: statePrior(state, action),
 		})
 	}
 
@@ -177,12 +334,34 @@ func (st *StateTree) SetDB(db Database) *StateTree {
 	return st
 }
 
+// DebugState is called with Bootstrap when a simulation starts, Expand
+// when a node is created and CurrentState after every action.
+//
+// Deprecated: use Subscribe.
 func (st *StateTree) DebugState(f func(n NodeDebug, debug Debug)) {
-	st.debugState = f
+	if st.debugState != nil {
+		st.debugState()
+	}
+	st.debugState = st.Subscribe(func(e Event) {
+		switch e.Kind {
+		case IterationStarted:
+			f(NodeDebug{State: e.State}, Bootstrap)
+		case NodeExpanded:
+			f(NodeDebug{State: e.State, Id: e.NodeID}, Expand)
+		case ActionSelected:
+			f(NodeDebug{State: e.State, Id: e.NodeID}, CurrentState)
+		}
+	}, IterationStarted, NodeExpanded, ActionSelected)
 }
 
+// Deprecated: use Subscribe with ActionSelected.
 func (st *StateTree) DebugAction(f func(actions []*Action, selected *Action)) {
-	st.debugActions = f
+	if st.debugAction != nil {
+		st.debugAction()
+	}
+	st.debugAction = st.Subscribe(func(e Event) {
+		f(e.Actions, e.Action)
+	}, ActionSelected)
 }
 
 type ControllerRequest struct {
@@ -207,61 +386,302 @@ func (st *StateTree) Controller(f func(req ControllerRequest) ControllerResponse
 type StateTreeConfig struct {
 	MaxTimeout    *time.Duration
 	MaxIterations int
+	// Context end training once it is done, checked before every batch of
+	// simulations, nil is never done
+	Context context.Context
+	// Solver mark terminal outcomes as proven and propagate them upward,
+	// training stops as soon as the root is proven
+	Solver bool
+	// Rave track All-Moves-As-First statistics and blend them into selection
+	Rave bool
+	// RaveBeta weight of the AMAF value for an action, defaults to
+	// RaveSchedule(DefaultRaveEquivalence)
+	RaveBeta func(action *Action) float64
+	// WideningK and WideningAlpha enable progressive widening when WideningK
+	// is positive, a node visited n times considers ceil(k * n^alpha) actions
+	WideningK     float64
+	WideningAlpha float64
+	// PUCT replace the UCB selection by Q + c * prior * sqrt(N) / (1 + n)
+	// using this value as c
+	PUCT float64
+	// ProgressiveBias add weight * prior / (n + 1) to the selection value
+	ProgressiveBias float64
+	// EvaluatorBatch number of simulations whose leaves are sent together
+	// to the evaluator, defaults to 1
+	EvaluatorBatch int
+	// ReuseTree keep the nodes in memory between calls, every Train drops
+	// the ones that can no longer be reached from the state it starts from
+	ReuseTree bool
+	// Backup how results are backpropagated across transpositions
+	Backup Backup
+	// CyclePolicy what to do when a simulation reaches a state twice
+	CyclePolicy CyclePolicy
+	// CyclePenalty score of a simulation ended by CyclePenalize
+	CyclePenalty float64
+	// MaxDepth number of actions after which a simulation stops and
+	// evaluates the state, see EvaluableState
+	MaxDepth int
+	// Discount applied to rewards for every action they are away from,
+	// defaults to 1
+	Discount float64
+	// SinglePlayer (SP-MCTS) track the best and squared returns of every
+	// action, add a variance term to selection and make Search pick the
+	// action with the best observed outcome
+	SinglePlayer bool
+	// SinglePlayerD constant added to the variance term, keeping actions
+	// with few visits attractive
+	SinglePlayerD float64
+	// Normalize scale returns into [0,1] before they are backpropagated
+	Normalize Normalization
+	// ReportEvery number of playouts between two progress reports, defaults
+	// to DefaultReportEvery, see SetReporter
+	ReportEvery int
+	// Reporter receive the progress reports of this call instead of the
+	// reporter set by SetReporter
+	Reporter Reporter
+	// StopWhenUnreachable end training once the most visited action of the
+	// root cannot be overtaken within the remaining MaxIterations. MaxDepth
+	// bounds the visits a playout adds, without it the action also has to
+	// lead the playouts of the call by more than the remaining ones.
+	StopWhenUnreachable bool
+	// StopWindow end training once the best action of the root, its share
+	// of the visits and its lower bound did not move by more than
+	// StopTolerance during this number of playouts
+	StopWindow    int
+	StopTolerance float64
 }
 
 func (st *StateTree) PlayTurn(state State) bool {
 
-	node, _ := st.getOrCreateNode(state, nil)
+	node, _ := st.getOrCreateNode(state, st.retained, StateTreeConfig{})
 
-	currentAction := node.selectAction(st.stats)
+	currentAction := node.selectAction(st.stats, StateTreeConfig{}, partial(node, state, StateTreeConfig{}))
 
 	state.PlayAction(currentAction.ID)
 
-	result := state.TurnResult(TurnRequest{Depth: 0})
+	result := state.TurnResult(TurnRequest{Depth: 1, Rand: st.rand})
 	return result.EndGame
 }
 
 func (st *StateTree) Train(s State, config StateTreeConfig) {
-	for i := 0; i < config.MaxIterations; i++ {
-		st.playGame(s)
+	st.train(s, config)
+}
+
+// train run the simulations and return the nodes visited by them
+func (st *StateTree) train(s State, config StateTreeConfig) (playouts int, visited map[string]bool, reason StopReason) {
+	if config.ReuseTree {
+		st.reroot(s.ID())
+	} else {
+		// the retained nodes would shadow the ones this training stores
+		st.retained = nil
+	}
+	start := time.Now()
+	defer st.trackProgress(s, config)()
+	visited = make(map[string]bool, 0)
+	stop := newConvergence(config)
+	batch := config.evaluatorBatch()
+	for i := 0; i < config.MaxIterations; i += batch {
+		if config.Context != nil && config.Context.Err() != nil {
+			reason = StopCancelled
+			break
+		}
+		if remaining := config.MaxIterations - i; remaining < batch {
+			batch = remaining
+		}
+		sims := st.playGames(s, config, batch)
+		playouts += len(sims)
+		for id := range visitedNodes(sims) {
+			visited[id] = true
+		}
+		if config.Solver && st.proof(s, config) != Unproven {
+			reason = StopProven
+			break
+		}
+		if config.MaxTimeout != nil && time.Since(start) >= *config.MaxTimeout {
+			reason = StopTimeout
+			break
+		}
+		if config.StopWhenUnreachable || config.StopWindow > 0 {
+			root, _ := st.findNode(s.ID(), st.retained)
+			if r, ok := stop.check(root, sims, playouts, config.MaxIterations-playouts, config); ok {
+				reason = r
+				break
+			}
+		}
 	}
+	st.emit(Event{Kind: TrainingStopped, Reason: reason})
+	return playouts, visited, reason
 }
 
 func (st *StateTree) PlayGame(s State) {
+	defer st.trackProgress(s, StateTreeConfig{})()
 	for {
-		res := st.controller(st.playGame(s))
+		res := st.controller(st.playGame(s, StateTreeConfig{}))
 		if !res.Restart {
 			break
 		}
 	}
 }
 
-func (st *StateTree) playGame(s State) ControllerRequest {
-	state := s.Copy()
+// simulation is a single descent from the root, until the end of the game
+// or until a leaf that has to be evaluated
+type simulation struct {
+	state       State
+	nodeList    []*Node
+	actionList  []*Action
+	solverSteps []solverStep
+	leaf        *Node
+	// score at the end of the simulation, either the game result or an
+	// evaluation of the last state
+	score float64
+	// rewards of the TurnResult after each action
+	rewards []float64
+	// nodes updated outside of the path
+	touched []*Node
+}
+
+func (st *StateTree) playGame(s State, config StateTreeConfig) ControllerRequest {
+	sims := st.playGames(s, config, 1)
+	return ControllerRequest{
+		State: sims[0].state,
+	}
+}
 
+// playGames run n simulations sharing the same nodes, the leaves they reach
+// are sent to the evaluator as a single batch
+func (st *StateTree) playGames(s State, config StateTreeConfig, n int) []*simulation {
 	nodeMap := make(map[string]*Node, 0)
-	actionList := make([]*Action, 0)
+	if config.ReuseTree {
+		if st.retained == nil {
+			st.retained = make(map[string]*Node, 0)
+		}
+		nodeMap = st.retained
+	}
+	leaves := make(map[string]bool, 0)
+
+	sims := make([]*simulation, 0, n)
+	for i := 0; i < n; i++ {
+		sims = append(sims, st.descend(s, config, nodeMap, leaves))
+	}
+	st.evaluateLeaves(sims)
+	for _, sim := range sims {
+		st.backpropagate(sim, config, nodeMap)
+	}
+	visited := visitedNodes(sims)
+	flushStart := time.Now()
+	for key, val := range visited {
+		_ = st.add(key, val.toDB())
+	}
+	if config.Normalize == NormalizeGlobal && st.bounds != nil {
+		_ = st.add(boundsKey, st.bounds.toDB())
+	}
+	st.emit(Event{Kind: StoreFlushed, Nodes: len(visited), Elapsed: time.Since(flushStart)})
+	return sims
+}
+
+func visitedNodes(sims []*simulation) map[string]*Node {
+	nodeMap := make(map[string]*Node, 0)
+	for _, sim := range sims {
+		for _, node := range sim.nodeList {
+			nodeMap[node.id] = node
+		}
+		if sim.leaf != nil {
+			nodeMap[sim.leaf.id] = sim.leaf
+		}
+		for _, node := range sim.touched {
+			nodeMap[node.id] = node
+		}
+	}
+	return nodeMap
+}
 
-	depth := 0
+func (st *StateTree) descend(s State, config StateTreeConfig, nodeMap map[string]*Node, leaves map[string]bool) *simulation {
+	sim := &simulation{
+		state:       s.Copy(),
+		nodeList:    make([]*Node, 0),
+		actionList:  make([]*Action, 0),
+		solverSteps: make([]solverStep, 0),
+	}
+	state := sim.state
+	st.emit(Event{Kind: IterationStarted, State: state})
+
+	var parent *Node
+	onPath := make(map[string]bool, 0)
 	for {
-		node, newNode := st.getOrCreateNode(state, nodeMap)
+		node, newNode := st.getOrCreateNode(state, nodeMap, config)
 		if newNode {
-			depth++
+			st.emit(Event{Kind: NodeExpanded, State: state, NodeID: node.id})
+		}
+		if parent != nil {
+			parent.addChild(node.id)
+			if config.Backup != BackupPath {
+				st.dag.add(edge{parent: parent.id, action: sim.actionList[len(sim.actionList)-1].ID}, node.id)
+			}
+		}
+		parent = node
+		if st.evaluator != nil && (newNode || leaves[node.id]) {
+			leaves[node.id] = true
+			nodeMap[node.id] = node
+			sim.leaf = node
+			return sim
+		}
+		if config.MaxDepth > 0 && len(sim.actionList) >= config.MaxDepth {
+			sim.score = evaluate(state)
+			return sim
+		}
+		if config.CyclePolicy != CycleIgnore && onPath[node.id] {
+			sim.score = config.cycleScore()
+			return sim
+		}
+		onPath[node.id] = true
+		if config.widening() {
+			st.widen(node, state, config)
 		}
-		currentAction := node.selectAction(st.stats)
-
-		state.PlayAction(currentAction.ID)
-		state.PlaySideEffects()
 
-		result := state.TurnResult(TurnRequest{Depth: depth})
+		candidates := node
+		nodePartial := partial(node, state, config)
+		var currentAction *Action
+		var result TurnResult
+		for {
+			currentAction = candidates.selectAction(st.stats, config, nodePartial)
+
+			var before State
+			if config.Solver || config.CyclePolicy == CycleForbid {
+				before = state.Copy()
+			}
+
+			state.PlayAction(currentAction.ID)
+			st.playSideEffects(state)
+
+			result = state.TurnResult(TurnRequest{Depth: len(sim.actionList) + 1, Rand: st.rand})
+
+			// play another action when this one goes back to a state of the path
+			if config.CyclePolicy == CycleForbid && !result.EndGame && onPath[state.ID()] && len(candidates.Actions) > 1 {
+				state = before
+				sim.state = before
+				candidates = candidates.without(currentAction)
+				continue
+			}
+			if config.Solver {
+				sim.solverSteps = append(sim.solverSteps, solverStep{action: currentAction, state: before})
+			}
+			break
+		}
 
-		// new node
-		st.debugActions(node.Actions, currentAction)
-		st.debugState(NodeDebug{State: state, Id: node.id}, CurrentState)
+		st.emit(Event{
+			Kind:    ActionSelected,
+			State:   state,
+			NodeID:  node.id,
+			Actions: node.Actions,
+			Action:  currentAction,
+			Depth:   len(sim.actionList) + 1,
+		})
 
-		actionList = append(actionList, currentAction)
+		sim.actionList = append(sim.actionList, currentAction)
+		sim.nodeList = append(sim.nodeList, node)
+		sim.rewards = append(sim.rewards, result.Reward)
 
-		for _, action := range actionList {
+		for _, action := range sim.distinctActions() {
 			action.NVisited++
 		}
 		st.stats.NVisited++
@@ -270,16 +690,37 @@ func (st *StateTree) playGame(s State) ControllerRequest {
 			break
 		}
 	}
-	gameResult := state.GameResult()
-	for _, action := range actionList {
-		action.Score += gameResult.Score
+	sim.score = float64(state.GameResult().Score)
+	st.emit(Event{Kind: GameFinished, State: state, Score: sim.score, Depth: len(sim.actionList)})
+	return sim
+}
+
+func (st *StateTree) backpropagate(sim *simulation, config StateTreeConfig, nodeMap map[string]*Node) {
+	raw := sim.returns(config.discount())
+	returns := st.normalize(sim, raw, config)
+	seen := make(map[*Action]bool, len(sim.actionList))
+	for i, action := range sim.actionList {
+		if seen[action] {
+			continue
+		}
+		seen[action] = true
+		action.Score += returns[i]
+	}
+	switch config.Backup {
+	case BackupAllParents:
+		st.backupAllParents(sim, raw, config, nodeMap)
+	case BackupChildValue:
+		st.backupChildValue(sim, config, nodeMap)
 	}
-	for key, val := range nodeMap {
-		_ = st.db.Add(key, val.toDB())
+	if config.Rave {
+		updateAMAF(sim.nodeList, sim.actionList, returns)
 	}
-
-	return ControllerRequest{
-		State: state,
+	if config.SinglePlayer {
+		st.updateSinglePlayer(sim, returns)
+	}
+	st.emit(Event{Kind: Backpropagated, State: sim.state, Score: sim.score, Depth: len(sim.actionList)})
+	if config.Solver {
+		st.solve(sim.solverSteps, nodeMap, config)
 	}
 }
 
@@ -298,11 +739,17 @@ type GameResult struct {
 }
 
 type TurnRequest struct {
+	// Depth number of actions played since the start of the simulation
 	Depth int
+	// Rand source of randomness of the tree, see StateTree.Seed
+	Rand *rand.Rand
 }
 
 type TurnResult struct {
 	EndGame bool
+	// Reward immediate reward of the action that was just played, added to
+	// the discounted return of the actions leading to it
+	Reward float64
 }
 
 func (a Action) GetNVisited() int {
@@ -311,16 +758,14 @@ func (a Action) GetNVisited() int {
 
 func New() *StateTree {
 	return &StateTree{
-		debugState: func(n NodeDebug, debug Debug) {
-			// default
-		},
-		debugActions: func(actions []*Action, selected *Action) {
-
-		},
 		controller: func(req ControllerRequest) ControllerResponse {
 			return ControllerResponse{}
 		},
 		db:    DefaultMemoryDB{nodeMap: map[string]string{}},
 		stats: &rootStats{NVisited: 0},
+		dag:   newDAG(),
+
+		trajectories: map[string]Trajectory{},
+		rand:         rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
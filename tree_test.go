@@ -1,8 +1,12 @@
 package tree
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -19,3 +23,843 @@ func TestMCT2(t *testing.T) {
 	fmt.Println(fSelection(0.0, 997440, 1500000))
 	fmt.Println(fSelection(0.0, 997440, 1500000))
 }
+
+func TestNodeToDB(t *testing.T) {
+	node := Node{
+		Actions: []*Action{
+			{ID: "a", Score: 3, NVisited: 5},
+			{ID: "b", Score: -1, NVisited: 2, Proof: ProvenLoss, AMAFScore: 4, AMAFNVisited: 7},
+		},
+		id: "root",
+	}
+	assert.Equal(t, "a;5;3;b;2;-1,p=1,an=7,as=4", node.toDB())
+	assert.Equal(t, &node, parseToNode("root", node.toDB()))
+	assert.Equal(t, []*Action{{ID: "a", Score: 3, NVisited: 5}}, parseToNode("root", "a;5;3").Actions)
+
+	// only the priors of the actions not added yet are stored
+	node.priors = map[string]float64{"a": 0.5, "c": 0.25, "d": 0.25}
+	assert.Equal(t, "#p=c=0.25,d=0.25;a;5;3;b;2;-1,p=1,an=7,as=4", node.toDB())
+	assert.Equal(t, map[string]float64{"c": 0.25, "d": 0.25}, parseToNode("root", node.toDB()).priors)
+}
+
+func TestUpdateAMAF(t *testing.T) {
+	first := &Node{Actions: []*Action{{ID: "a"}, {ID: "b"}, {ID: "c"}}}
+	second := &Node{Actions: []*Action{{ID: "a"}, {ID: "c"}}}
+
+	updateAMAF([]*Node{first, second}, []*Action{first.Actions[0], second.Actions[1]}, []float64{2, 2})
+
+	assert.Equal(t, []*Action{
+		{ID: "a", AMAFNVisited: 1, AMAFScore: 2},
+		{ID: "b"},
+		{ID: "c", AMAFNVisited: 1, AMAFScore: 2},
+	}, first.Actions)
+	assert.Equal(t, []*Action{
+		{ID: "a"},
+		{ID: "c", AMAFNVisited: 1, AMAFScore: 2},
+	}, second.Actions)
+}
+
+func TestProvenAction(t *testing.T) {
+	// a win listed after an unproven action settles the node
+	node := &Node{Actions: []*Action{{ID: "a"}, {ID: "b", Proof: ProvenWin}}}
+	assert.Equal(t, ProvenWin, node.proof())
+	assert.Equal(t, "b", node.provenAction(false).ID)
+	assert.Equal(t, "b", node.selectAction(&rootStats{NVisited: 1}, StateTreeConfig{}, false).ID)
+
+	node = &Node{Actions: []*Action{{ID: "a", Proof: ProvenLoss}, {ID: "b"}}}
+	assert.Nil(t, node.provenAction(false))
+	node.Actions[1].Proof = ProvenDraw
+	assert.Equal(t, "b", node.provenAction(false).ID)
+}
+
+// choiceState end the game with its first action, the prior favours the
+// losing one
+type choiceState struct {
+	played string
+}
+
+func (c *choiceState) ID() string                        { return "choice/" + c.played }
+func (c *choiceState) PossibleActions() []string         { return []string{"lose", "win"} }
+func (c *choiceState) Copy() State                       { return &choiceState{played: c.played} }
+func (c *choiceState) PlayAction(action string)          { c.played = action }
+func (c *choiceState) PlaySideEffects()                  {}
+func (c *choiceState) TurnResult(TurnRequest) TurnResult { return TurnResult{EndGame: c.played != ""} }
+func (c *choiceState) Prior(action string) float64 {
+	if action == "lose" {
+		return 0.9
+	}
+	return 0.1
+}
+
+func (c *choiceState) GameResult() GameResult {
+	if c.played == "win" {
+		return GameResult{Score: 1}
+	}
+	return GameResult{Score: -1}
+}
+
+func TestSolverWithWidening(t *testing.T) {
+	stateTree := New()
+	result := stateTree.Search(&choiceState{}, StateTreeConfig{
+		MaxIterations: 100,
+		Solver:        true,
+		WideningK:     1,
+		WideningAlpha: 0.5,
+	})
+
+	// the loss widened first does not settle the root while win is missing
+	assert.Equal(t, StopProven, result.StopReason)
+	assert.Greater(t, result.Playouts, 1)
+	assert.Equal(t, ProvenWin, stateTree.Proof(&choiceState{}))
+	assert.Equal(t, "win", result.BestAction)
+
+	partialRoot := &Node{Actions: []*Action{{ID: "lose", Proof: ProvenLoss}}}
+	assert.Nil(t, partialRoot.provenAction(true))
+	assert.Equal(t, Unproven, nodeProof(partialRoot, &choiceState{}, StateTreeConfig{}))
+}
+
+func TestRaveSchedule(t *testing.T) {
+	beta := RaveSchedule(500)
+	assert.Equal(t, 1.0, beta(&Action{}))
+	assert.Equal(t, 0.5, beta(&Action{NVisited: 500}))
+	assert.Equal(t, 0.5, StateTreeConfig{}.raveBeta()(&Action{NVisited: DefaultRaveEquivalence}))
+	assert.Equal(t, 0.25, StateTreeConfig{RaveBeta: func(*Action) float64 { return 0.25 }}.raveBeta()(&Action{}))
+}
+
+func TestRaveSelection(t *testing.T) {
+	// a single visit of the parent leaves the exploration term out
+	action := &Action{Score: 4, NVisited: 4, AMAFScore: 0, AMAFNVisited: 2}
+	assert.Equal(t, 1.0, raveSelection(action, 1, 0))
+	assert.Equal(t, 0.5, raveSelection(action, 1, 0.5))
+	assert.Equal(t, 0.0, raveSelection(action, 1, 1))
+	assert.Equal(t, 0.75, raveSelection(&Action{AMAFScore: 3, AMAFNVisited: 4}, 1, 0.5))
+}
+
+func TestTrainWithRave(t *testing.T) {
+	stateTree := New()
+	stateTree.Train(&lineState{}, StateTreeConfig{MaxIterations: 200, Rave: true})
+
+	root, _ := stateTree.getOrCreateNode(&lineState{}, nil, StateTreeConfig{})
+	right := root.Actions[1]
+	assert.Equal(t, "right", right.ID)
+	assert.Greater(t, right.AMAFNVisited, 0)
+	for _, action := range root.Actions {
+		assert.LessOrEqual(t, action.NVisited, right.NVisited)
+	}
+}
+
+// lineState is a walk on a line, reaching position 3 wins and running out
+// of moves loses
+type lineState struct {
+	pos   int
+	moves int
+	// deepest TurnRequest seen, shared by the copies
+	maxDepth *int
+}
+
+func (l *lineState) ID() string {
+	return fmt.Sprintf("%d", l.pos)
+}
+
+func (l *lineState) PossibleActions() []string {
+	return []string{"left", "right", "stay"}
+}
+
+func (l *lineState) Copy() State {
+	c := *l
+	return &c
+}
+
+func (l *lineState) PlayAction(action string) {
+	switch action {
+	case "left":
+		l.pos--
+	case "right":
+		l.pos++
+	}
+	l.moves++
+}
+
+func (l *lineState) PlaySideEffects() {}
+
+func (l *lineState) TurnResult(req TurnRequest) TurnResult {
+	if l.maxDepth != nil && req.Depth > *l.maxDepth {
+		*l.maxDepth = req.Depth
+	}
+	return TurnResult{EndGame: l.pos == 3 || l.moves >= 10}
+}
+
+func (l *lineState) GameResult() GameResult {
+	if l.pos == 3 {
+		return GameResult{Score: 1}
+	}
+	return GameResult{Score: -1}
+}
+
+func (l *lineState) Evaluate() float64 {
+	return float64(l.pos) / 3
+}
+
+func TestWiden(t *testing.T) {
+	stateTree := New()
+	config := StateTreeConfig{WideningK: 1, WideningAlpha: 0.5}
+	node := &Node{}
+
+	stateTree.widen(node, &lineState{}, config)
+	assert.Len(t, node.Actions, 1)
+
+	node.Actions[0].NVisited = 4
+	stateTree.widen(node, &lineState{}, config)
+	assert.Len(t, node.Actions, 2)
+
+	node.Actions[1].NVisited = 12
+	stateTree.widen(node, &lineState{}, config)
+	assert.Equal(t, []string{"left", "right", "stay"}, []string{node.Actions[0].ID, node.Actions[1].ID, node.Actions[2].ID})
+	assert.True(t, isFullyWidened(node, &lineState{}))
+}
+
+// sampledLineState draw its actions instead of listing them
+type sampledLineState struct {
+	lineState
+}
+
+func (l *sampledLineState) SampleAction(req SampleRequest) string {
+	return l.PossibleActions()[req.Rand.Intn(3)]
+}
+
+func TestWidenSampler(t *testing.T) {
+	config := StateTreeConfig{WideningK: 3, WideningAlpha: 0}
+	sampled := func() []string {
+		node := &Node{}
+		New().Seed(3).widen(node, &sampledLineState{}, config)
+		ids := make([]string, 0)
+		for _, action := range node.Actions {
+			ids = append(ids, action.ID)
+		}
+		return ids
+	}
+	first := sampled()
+	assert.ElementsMatch(t, []string{"left", "right", "stay"}, first)
+	assert.Equal(t, first, sampled())
+}
+
+func TestTrainWithWidening(t *testing.T) {
+	stateTree := New()
+	stateTree.Train(&lineState{}, StateTreeConfig{
+		MaxIterations: 200,
+		WideningK:     1,
+		WideningAlpha: 0.5,
+	})
+
+	root, _ := stateTree.getOrCreateNode(&lineState{}, nil, StateTreeConfig{})
+	assert.Len(t, root.Actions, 3)
+	assert.Equal(t, "right", root.Actions[1].ID)
+	for _, action := range root.Actions {
+		assert.LessOrEqual(t, action.NVisited, root.Actions[1].NVisited)
+	}
+}
+
+func TestPUCT(t *testing.T) {
+	assert.Equal(t, 1.0, puctSelection(&Action{Prior: 0.5}, 16, 0.5))
+	assert.Equal(t, 1.5, puctSelection(&Action{Score: 3, NVisited: 3, Prior: 0.5}, 16, 1))
+	assert.Equal(t, 0.25, progressiveBias(&Action{NVisited: 1, Prior: 0.5}, 1))
+	assert.Equal(t, "a;1;0,pr=0.25", Node{Actions: []*Action{{ID: "a", NVisited: 1, Prior: 0.25}}}.toDB())
+}
+
+type lineEvaluator struct {
+	batches []int
+}
+
+func (e *lineEvaluator) Evaluate(states []State) []Evaluation {
+	e.batches = append(e.batches, len(states))
+	evaluations := make([]Evaluation, 0)
+	for _, state := range states {
+		evaluations = append(evaluations, Evaluation{
+			Value:  float64(state.(*lineState).pos) / 3,
+			Priors: map[string]float64{"left": 0.1, "right": 0.8, "stay": 0.1},
+		})
+	}
+	return evaluations
+}
+
+func TestTrainWithEvaluator(t *testing.T) {
+	evaluator := &lineEvaluator{}
+	stateTree := New().SetEvaluator(evaluator)
+	stateTree.Train(&lineState{}, StateTreeConfig{
+		MaxIterations:  20,
+		EvaluatorBatch: 4,
+		PUCT:           1,
+	})
+
+	assert.Equal(t, []int{1, 2, 1}, evaluator.batches)
+	root, _ := stateTree.getOrCreateNode(&lineState{}, nil, StateTreeConfig{})
+	assert.Equal(t, 0.8, root.Actions[1].Prior)
+	assert.Greater(t, root.Actions[1].NVisited, root.Actions[0].NVisited)
+}
+
+// shortEvaluator drop the last state of every batch
+type shortEvaluator struct {
+	lineEvaluator
+}
+
+func (e *shortEvaluator) Evaluate(states []State) []Evaluation {
+	return e.lineEvaluator.Evaluate(states)[:len(states)-1]
+}
+
+func TestShortEvaluator(t *testing.T) {
+	stateTree := New().SetEvaluator(&shortEvaluator{})
+	assert.PanicsWithValue(t, "tree: evaluator returned 0 evaluations for 1 states", func() {
+		stateTree.Train(&lineState{}, StateTreeConfig{MaxIterations: 20, EvaluatorBatch: 4})
+	})
+}
+
+func TestTrainWithEvaluatorAndWidening(t *testing.T) {
+	stateTree := New().SetEvaluator(&lineEvaluator{})
+	stateTree.Train(&lineState{}, StateTreeConfig{
+		MaxIterations: 20,
+		PUCT:          1,
+		WideningK:     1,
+		WideningAlpha: 0.5,
+	})
+
+	// the leaf has no action yet when evaluated, its priors are used once
+	// widening adds them
+	root, _ := stateTree.getOrCreateNode(&lineState{}, nil, StateTreeConfig{})
+	assert.Equal(t, "right", root.Actions[0].ID)
+	for _, action := range root.Actions {
+		assert.Equal(t, map[string]float64{"left": 0.1, "right": 0.8, "stay": 0.1}[action.ID], action.Prior)
+	}
+}
+
+func TestSelfPlay(t *testing.T) {
+	stateTree := New()
+	examples := stateTree.SelfPlay(&lineState{}, SelfPlayConfig{
+		Train:            StateTreeConfig{MaxIterations: 100},
+		TemperatureMoves: 2,
+	})
+
+	assert.NotEmpty(t, examples)
+	for _, example := range examples {
+		total := 0.0
+		for _, p := range example.Policy {
+			total += p
+		}
+		assert.InDelta(t, 1, total, 1e-9)
+		assert.Equal(t, examples[0].Value, example.Value)
+	}
+
+	var b bytes.Buffer
+	assert.NoError(t, WriteExamples(&b, examples))
+	read, err := ReadExamples(&b)
+	assert.NoError(t, err)
+	assert.Equal(t, examples, read)
+}
+
+func TestReroot(t *testing.T) {
+	stateTree := New()
+	stateTree.retained = map[string]*Node{
+		"root":  {id: "root", children: map[string]bool{"a": true, "b": true}},
+		"a":     {id: "a", children: map[string]bool{"a1": true}},
+		"a1":    {id: "a1", children: map[string]bool{"a": true}},
+		"b":     {id: "b"},
+		"other": {id: "other"},
+	}
+
+	stateTree.reroot("a")
+	assert.Len(t, stateTree.retained, 2)
+	assert.Contains(t, stateTree.retained, "a")
+	assert.Contains(t, stateTree.retained, "a1")
+
+	stateTree.reroot("unknown")
+	assert.Empty(t, stateTree.retained)
+}
+
+func TestTrainReuseTree(t *testing.T) {
+	stateTree := New()
+	config := StateTreeConfig{MaxIterations: 50, ReuseTree: true}
+
+	state := &lineState{}
+	stateTree.Train(state, config)
+	root := stateTree.retained["0"]
+	assert.NotNil(t, root)
+
+	stateTree.PlayTurn(state)
+	stateTree.Train(state, config)
+	assert.Same(t, root, stateTree.retained["0"])
+
+	// a training without reuse must not leave the stale nodes in the way
+	visits := root.nVisited()
+	stateTree.Train(&lineState{}, StateTreeConfig{MaxIterations: 50})
+	assert.Nil(t, stateTree.retained)
+	stats, ok := stateTree.NodeStats("0")
+	assert.True(t, ok)
+	total := 0
+	for _, action := range stats {
+		total += action.NVisited
+	}
+	assert.Greater(t, total, visits)
+	assert.Greater(t, stateTree.Search(&lineState{}, StateTreeConfig{}).Actions[0].NVisited, 0)
+}
+
+func TestSearch(t *testing.T) {
+	stateTree := New()
+	state := &lineState{}
+	result := stateTree.Search(state, StateTreeConfig{MaxIterations: 200})
+
+	assert.Equal(t, 0, state.pos)
+	assert.Equal(t, 200, result.Playouts)
+	assert.Equal(t, "right", result.BestAction)
+	assert.Equal(t, []string{"right", "right", "right"}, result.PrincipalVariation)
+	assert.Len(t, result.Actions, 3)
+	assert.Equal(t, "right", result.Actions[0].ID)
+	assert.LessOrEqual(t, result.Actions[0].Lower, result.Actions[0].Mean)
+	assert.GreaterOrEqual(t, result.Actions[0].Upper, result.Actions[0].Mean)
+	assert.Greater(t, result.NodesVisited, 3)
+}
+
+func TestBackupAllParents(t *testing.T) {
+	stateTree := New()
+	first := &Node{id: "first", Actions: []*Action{{ID: "a", NVisited: 1}}}
+	other := &Node{id: "other", Actions: []*Action{{ID: "b", NVisited: 1}}}
+	child := &Node{id: "child", Actions: []*Action{{ID: "c"}}}
+	stateTree.dag.add(edge{parent: "first", action: "a"}, "child")
+	stateTree.dag.add(edge{parent: "other", action: "b"}, "child")
+
+	nodeMap := map[string]*Node{"first": first, "other": other, "child": child}
+	sim := &simulation{
+		nodeList:   []*Node{first, child},
+		actionList: []*Action{first.Actions[0], child.Actions[0]},
+	}
+	sim.score = 1
+	stateTree.backupAllParents(sim, []float64{1, 1}, StateTreeConfig{}, nodeMap)
+
+	assert.Equal(t, &Action{ID: "b", NVisited: 2, Score: 1}, other.Actions[0])
+	assert.Equal(t, &Action{ID: "a", NVisited: 1}, first.Actions[0])
+	assert.Equal(t, []*Node{other}, sim.touched)
+}
+
+func TestBackupAllParentsRewards(t *testing.T) {
+	stateTree := New()
+	first := &Node{id: "first", Actions: []*Action{{ID: "a"}}}
+	other := &Node{id: "other", Actions: []*Action{{ID: "b"}}}
+	child := &Node{id: "child", Actions: []*Action{{ID: "c"}}}
+	stateTree.dag.add(edge{parent: "first", action: "a"}, "child")
+	stateTree.dag.add(edge{parent: "other", action: "b"}, "child")
+
+	sim := &simulation{
+		nodeList:   []*Node{first, child},
+		actionList: []*Action{first.Actions[0], child.Actions[0]},
+		rewards:    []float64{5, 0},
+		score:      1,
+	}
+	nodeMap := map[string]*Node{"first": first, "other": other, "child": child}
+	stateTree.backpropagate(sim, StateTreeConfig{Backup: BackupAllParents, Discount: 0.5}, nodeMap)
+
+	// the reward of a is not credited to b, only the discounted value of child
+	assert.Equal(t, 5.5, first.Actions[0].Score)
+	assert.Equal(t, &Action{ID: "b", NVisited: 1, Score: 0.5}, other.Actions[0])
+}
+
+func TestBackupChildValue(t *testing.T) {
+	stateTree := New()
+	root := &Node{id: "root", Actions: []*Action{{ID: "a", NVisited: 4, Score: 4}}}
+	left := &Node{id: "left", Actions: []*Action{{ID: "x", NVisited: 3, Score: -3}}}
+	right := &Node{id: "right", Actions: []*Action{{ID: "y", NVisited: 1, Score: 1}}}
+	stateTree.dag.add(edge{parent: "root", action: "a"}, "left")
+	stateTree.dag.add(edge{parent: "root", action: "a"}, "right")
+
+	nodeMap := map[string]*Node{"root": root, "left": left, "right": right}
+	sim := &simulation{
+		nodeList:   []*Node{root, left},
+		actionList: []*Action{root.Actions[0], left.Actions[0]},
+	}
+	stateTree.backupChildValue(sim, StateTreeConfig{}, nodeMap)
+
+	assert.Equal(t, -2.0, root.Actions[0].Score)
+	assert.Equal(t, -3.0, left.Actions[0].Score)
+}
+
+func TestBackupChildValueRewards(t *testing.T) {
+	stateTree := New()
+	root := &Node{id: "root", Actions: []*Action{{ID: "a", NVisited: 2, Score: 2}}}
+	child := &Node{id: "child", Actions: []*Action{{ID: "x", NVisited: 4, Score: 2}}}
+	stateTree.dag.add(edge{parent: "root", action: "a"}, "child")
+
+	nodeMap := map[string]*Node{"root": root, "child": child}
+	sim := &simulation{
+		nodeList:   []*Node{root},
+		actionList: []*Action{root.Actions[0]},
+		rewards:    []float64{1},
+	}
+	stateTree.backupChildValue(sim, StateTreeConfig{Discount: 0.5}, nodeMap)
+
+	// reward 1 plus half of the child mean 0.5, for both visits
+	assert.Equal(t, 2.5, root.Actions[0].Score)
+}
+
+func TestTrainDAG(t *testing.T) {
+	for _, backup := range []Backup{BackupAllParents, BackupChildValue} {
+		result := New().Search(&lineState{}, StateTreeConfig{MaxIterations: 300, Backup: backup})
+		assert.Equal(t, "right", result.BestAction)
+	}
+}
+
+func TestCyclePolicy(t *testing.T) {
+	stateTree := New()
+	stateTree.Train(&lineState{}, StateTreeConfig{MaxIterations: 100, CyclePolicy: CycleForbid})
+	root, _ := stateTree.getOrCreateNode(&lineState{}, nil, StateTreeConfig{})
+	assert.Equal(t, "stay", root.Actions[2].ID)
+	assert.Equal(t, 0, root.Actions[2].NVisited)
+
+	stateTree = New()
+	stateTree.Train(&lineState{}, StateTreeConfig{MaxIterations: 100, CyclePolicy: CyclePenalize, CyclePenalty: -5})
+	root, _ = stateTree.getOrCreateNode(&lineState{}, nil, StateTreeConfig{})
+	assert.Less(t, root.Actions[2].Score/float64(root.Actions[2].NVisited), -1.0)
+}
+
+func TestDistinctActions(t *testing.T) {
+	a, b := &Action{ID: "a"}, &Action{ID: "b"}
+	sim := &simulation{actionList: []*Action{a, b, a}}
+	assert.Equal(t, []*Action{a, b}, sim.distinctActions())
+}
+
+func TestMaxDepth(t *testing.T) {
+	maxDepth := 0
+	result := New().Search(&lineState{maxDepth: &maxDepth}, StateTreeConfig{MaxIterations: 100, MaxDepth: 2})
+	assert.Equal(t, 2, maxDepth)
+	assert.Equal(t, "right", result.BestAction)
+
+	maxDepth = 0
+	New().Train(&lineState{maxDepth: &maxDepth}, StateTreeConfig{MaxIterations: 100})
+	assert.Equal(t, 10, maxDepth)
+}
+
+func TestReturns(t *testing.T) {
+	sim := &simulation{
+		actionList: []*Action{{}, {}, {}},
+		rewards:    []float64{1, 0, 2},
+		score:      4,
+	}
+	assert.Equal(t, []float64{7, 6, 6}, sim.returns(1))
+	assert.Equal(t, []float64{2.5, 3, 6}, sim.returns(0.5))
+}
+
+func TestSinglePlayer(t *testing.T) {
+	stateTree := New()
+	result := stateTree.Search(&lineState{}, StateTreeConfig{MaxIterations: 200, SinglePlayer: true, SinglePlayerD: 1, Discount: 0.9})
+	assert.Equal(t, "right", result.BestAction)
+	assert.Equal(t, []string{"right", "right", "right"}, result.BestTrajectory)
+
+	trajectory, ok := stateTree.BestTrajectory(&lineState{})
+	assert.True(t, ok)
+	assert.InDelta(t, 0.81, trajectory.Score, 1e-9)
+
+	assert.Equal(t, 1.0, varianceTerm(&Action{}, 1))
+	assert.Equal(t, 1.0, varianceTerm(&Action{NVisited: 2, Score: 2, SquaredScore: 4}, 0))
+}
+
+func TestNormalize(t *testing.T) {
+	node := &Node{id: "n", Actions: []*Action{{ID: "a", NVisited: 1}}, bounds: &bounds{min: -2, max: 6}}
+	assert.Equal(t, "#b=-2,6;a;1;0", node.toDB())
+	assert.Equal(t, node, parseToNode("n", node.toDB()))
+
+	stateTree := New()
+	sim := &simulation{nodeList: []*Node{{id: "x"}, {id: "y"}}, actionList: []*Action{{}, {}}}
+	assert.Equal(t, []float64{0.5, 0.5}, stateTree.normalize(sim, []float64{4, 4}, StateTreeConfig{Normalize: NormalizeGlobal}))
+	assert.Equal(t, []float64{1, 0}, stateTree.normalize(sim, []float64{8, 0}, StateTreeConfig{Normalize: NormalizeGlobal}))
+	assert.Equal(t, []float64{0.5, 0.5}, stateTree.normalize(sim, []float64{8, 0}, StateTreeConfig{Normalize: NormalizeNode}))
+	assert.Equal(t, []float64{0, 1}, stateTree.normalize(sim, []float64{4, 4}, StateTreeConfig{Normalize: NormalizeNode}))
+
+	stateTree.Train(&lineState{}, StateTreeConfig{MaxIterations: 10, Normalize: NormalizeGlobal})
+	val, ok := stateTree.db.Find(boundsKey)
+	assert.True(t, ok)
+	assert.Equal(t, "-1,8", val)
+}
+
+func TestEvents(t *testing.T) {
+	stateTree := New()
+	counts := make(map[EventKind]int, 0)
+	unsubscribe := stateTree.Subscribe(func(e Event) {
+		counts[e.Kind]++
+	})
+	finished := 0
+	stateTree.Subscribe(func(e Event) {
+		assert.Equal(t, GameFinished, e.Kind)
+		finished++
+	}, GameFinished)
+	events, stop := stateTree.Events(100, StoreFlushed)
+
+	stateTree.Train(&lineState{}, StateTreeConfig{MaxIterations: 10})
+	assert.Equal(t, 10, counts[IterationStarted])
+	assert.Equal(t, 10, counts[Backpropagated])
+	assert.Equal(t, 10, counts[GameFinished])
+	assert.Equal(t, 10, finished)
+	assert.Greater(t, counts[NodeExpanded], 0)
+	assert.Greater(t, counts[ActionSelected], 10)
+
+	stop()
+	flushed := 0
+	for e := range events {
+		assert.Equal(t, StoreFlushed, e.Kind)
+		flushed++
+	}
+	assert.Equal(t, 10, flushed)
+
+	unsubscribe()
+	stateTree.Train(&lineState{}, StateTreeConfig{MaxIterations: 10})
+	assert.Equal(t, 10, counts[IterationStarted])
+	assert.Equal(t, 20, finished)
+}
+
+func TestEventsStopDuringTraining(t *testing.T) {
+	stateTree := New()
+	events, stop := stateTree.Events(0)
+
+	trained := make(chan struct{})
+	go func() {
+		stateTree.Train(&lineState{}, StateTreeConfig{MaxIterations: 1000})
+		close(trained)
+	}()
+
+	// training blocks on the unbuffered channel until stop is called
+	for i := 0; i < 10; i++ {
+		<-events
+	}
+	stopped := make(chan struct{})
+	go func() {
+		stop()
+		stop()
+		close(stopped)
+	}()
+	<-stopped
+	<-trained
+	for range events {
+	}
+}
+
+func TestWriteDot(t *testing.T) {
+	stateTree := New()
+	stateTree.Train(&lineState{}, StateTreeConfig{MaxIterations: 50})
+
+	var b bytes.Buffer
+	assert.NoError(t, stateTree.WriteDot(&b, &lineState{}, DotConfig{MaxDepth: 1, MinVisits: 1}))
+	dot := b.String()
+	assert.True(t, strings.HasPrefix(dot, `digraph tree {
+  n0 [label="0\nn=`))
+	assert.Contains(t, dot, `n0 -> n1 [label="left\nn=`)
+	assert.Contains(t, dot, `n0 -> n0 [label="stay\nn=`)
+	assert.True(t, strings.HasSuffix(dot, "}\n"))
+}
+
+// randomLineState draw from the source of the tree on every side effect
+type randomLineState struct {
+	lineState
+}
+
+func (l *randomLineState) Copy() State {
+	return &randomLineState{lineState: l.lineState}
+}
+
+func (l *randomLineState) PlaySideEffectsWith(req SideEffectsRequest) {
+	req.Rand.Intn(2)
+}
+
+func TestWriteDotKeepsSeed(t *testing.T) {
+	train := func(render bool) int64 {
+		stateTree := New().Seed(1)
+		stateTree.Train(&randomLineState{}, StateTreeConfig{MaxIterations: 20})
+		if render {
+			assert.NoError(t, stateTree.WriteDot(&bytes.Buffer{}, &randomLineState{}, DotConfig{}))
+		}
+		return stateTree.rand.Int63()
+	}
+	assert.Equal(t, train(false), train(true))
+}
+
+func TestReporter(t *testing.T) {
+	reports := make([]Progress, 0)
+	var csvOut bytes.Buffer
+	csvReporter := NewCSVReporter(&csvOut)
+	stateTree := New().SetReporter(ReporterFunc(func(p Progress) {
+		reports = append(reports, p)
+		csvReporter.Report(p)
+	}))
+	stateTree.Train(&lineState{}, StateTreeConfig{MaxIterations: 50, ReportEvery: 20})
+
+	playouts := make([]int, 0)
+	for _, p := range reports {
+		playouts = append(playouts, p.Playouts)
+	}
+	assert.Equal(t, []int{20, 40, 50}, playouts)
+	last := reports[len(reports)-1]
+	assert.True(t, last.Done)
+	assert.Equal(t, "right", last.BestAction)
+	assert.True(t, last.NodesCreated > 0)
+	assert.True(t, last.AvgGameLength >= 1)
+
+	rows := strings.Split(strings.TrimSpace(csvOut.String()), "\n")
+	assert.Len(t, rows, 4)
+	assert.True(t, strings.HasPrefix(rows[0], "elapsed_ms,playouts,"))
+	assert.True(t, strings.HasSuffix(rows[3], ",true,iterations"))
+}
+
+func TestEarlyStop(t *testing.T) {
+	result := New().Search(&lineState{}, StateTreeConfig{MaxIterations: 10000})
+	assert.Equal(t, StopIterations, result.StopReason)
+	assert.Equal(t, 10000, result.Playouts)
+
+	result = New().Search(&lineState{}, StateTreeConfig{MaxIterations: 10000, MaxDepth: 10, StopWhenUnreachable: true})
+	assert.Equal(t, StopUnreachable, result.StopReason)
+	assert.Equal(t, "right", result.BestAction)
+	assert.True(t, result.Playouts < 10000)
+
+	result = New().Search(&lineState{}, StateTreeConfig{MaxIterations: 10000, StopWhenUnreachable: true})
+	assert.Equal(t, StopUnreachable, result.StopReason)
+	assert.Equal(t, "right", result.BestAction)
+	assert.True(t, result.Playouts < 10000)
+
+	result = New().Search(&lineState{}, StateTreeConfig{MaxIterations: 10000, StopWindow: 200})
+	assert.Equal(t, StopConverged, result.StopReason)
+	assert.Equal(t, "right", result.BestAction)
+	assert.True(t, result.Playouts < 10000)
+}
+
+func TestCancelTraining(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result := New().Search(&lineState{}, StateTreeConfig{MaxIterations: 100, Context: ctx})
+	assert.Equal(t, StopCancelled, result.StopReason)
+	assert.Equal(t, 0, result.Playouts)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	stateTree := New()
+	played := 0
+	stateTree.Subscribe(func(e Event) {
+		if played++; played == 10 {
+			cancel()
+		}
+	}, GameFinished)
+	result = stateTree.Search(&lineState{}, StateTreeConfig{MaxIterations: 100, Context: ctx})
+	assert.Equal(t, StopCancelled, result.StopReason)
+	assert.Equal(t, 10, result.Playouts)
+	assert.Equal(t, "cancelled", result.StopReason.String())
+}
+
+func TestStopUnreachableBound(t *testing.T) {
+	root := &Node{Actions: []*Action{{ID: "a", NVisited: 100}, {ID: "b", NVisited: 58}}}
+	stop := newConvergence(StateTreeConfig{})
+
+	// the playouts so far were short, a longer one can still show up
+	config := StateTreeConfig{MaxDepth: 10, StopWhenUnreachable: true}
+	_, ok := stop.check(root, nil, 10, 5, config)
+	assert.False(t, ok)
+	reason, ok := stop.check(root, nil, 10, 4, config)
+	assert.True(t, ok)
+	assert.Equal(t, StopUnreachable, reason)
+
+	// another parent can credit one more visit per playout
+	config.Backup = BackupAllParents
+	_, ok = stop.check(root, nil, 10, 4, config)
+	assert.False(t, ok)
+
+	// without MaxDepth each playout counts once for the action it started with
+	config = StateTreeConfig{StopWhenUnreachable: true}
+	var sims []*simulation
+	for i := 0; i < 7; i++ {
+		sims = append(sims, &simulation{actionList: []*Action{root.Actions[0]}})
+	}
+	sims = append(sims, &simulation{actionList: []*Action{root.Actions[1]}})
+	_, ok = stop.check(root, sims, 18, 6, config)
+	assert.False(t, ok)
+	reason, ok = stop.check(root, nil, 18, 5, config)
+	assert.True(t, ok)
+	assert.Equal(t, StopUnreachable, reason)
+}
+
+func TestMetrics(t *testing.T) {
+	metrics := NewMetrics()
+	stateTree := New().SetMetrics(metrics)
+	stateTree.Train(&lineState{}, StateTreeConfig{MaxIterations: 50, ReuseTree: true})
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body := recorder.Body.String()
+	assert.Contains(t, body, "# TYPE sktree_playouts_total counter\nsktree_playouts_total 50\n")
+	assert.Contains(t, body, "sktree_game_length_count 50\n")
+	assert.Contains(t, body, "sktree_game_length_bucket{le=\"+Inf\"} 50\n")
+	assert.Contains(t, body, "# TYPE sktree_db_add_seconds histogram\n")
+	assert.Contains(t, body, "sktree_db_finds_total{result=\"miss\"} ")
+	assert.NotContains(t, body, "sktree_cache_hits_total 0\n")
+	assert.NotContains(t, body, "sktree_nodes_created_total 0\n")
+
+	stateTree.SetMetrics(nil)
+	stateTree.Train(&lineState{}, StateTreeConfig{MaxIterations: 10})
+	recorder = httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Contains(t, recorder.Body.String(), "sktree_playouts_total 50\n")
+}
+
+func TestMergeStore(t *testing.T) {
+	dst := NewMemoryDB()
+	_ = dst.Add("s", "#b=0,1;a;2;1.5,p=3;b;1;0")
+	_ = dst.Add(boundsKey, "0,1")
+	src := NewMemoryDB()
+	_ = src.Add("s", "#b=-1,0.5;a;1;0.5;c;4;2")
+	_ = src.Add("t", "a;1;1")
+	_ = src.Add(boundsKey, "-2,0")
+
+	merged, err := MergeStore(dst, src)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, merged)
+	s, _ := dst.Find("s")
+	assert.Equal(t, "#b=-1,1;a;3;2,p=3;b;1;0;c;4;2", s)
+	tNode, _ := dst.Find("t")
+	assert.Equal(t, "a;1;1", tNode)
+	b, _ := dst.Find(boundsKey)
+	assert.Equal(t, "-2,1", b)
+
+	stats, err := ReadStoreStats(dst)
+	assert.NoError(t, err)
+	assert.Equal(t, StoreStats{Nodes: 2, Actions: 4, Visits: 9, Proven: 1, Bytes: stats.Bytes}, stats)
+}
+
+func TestRegisterGame(t *testing.T) {
+	game := Game{
+		Name: "line",
+		New: func() State {
+			return &lineState{}
+		},
+		Encode: func(s State) ([]byte, error) {
+			return []byte(s.ID()), nil
+		},
+		Decode: func(data []byte) (State, error) {
+			var pos int
+			_, err := fmt.Sscanf(string(data), "%d", &pos)
+			return &lineState{pos: pos}, err
+		},
+		Render: func(s State) string {
+			return "pos " + s.ID()
+		},
+	}
+	RegisterGame(game)
+	assert.Contains(t, Games(), "line")
+	assert.Panics(t, func() { RegisterGame(game) })
+	assert.Panics(t, func() { RegisterGame(Game{Name: "incomplete"}) })
+
+	found, ok := LookupGame("line")
+	assert.True(t, ok)
+	state, err := found.Decode([]byte("2"))
+	assert.NoError(t, err)
+	assert.Equal(t, "pos 2", found.Render(state))
+	_, ok = LookupGame("chess")
+	assert.False(t, ok)
+}
+
+func TestBestAction(t *testing.T) {
+	assert.Equal(t, "", BestAction(nil))
+	// a proven loss is never picked, a proven win always is
+	stats := []ActionStats{{ID: "a", NVisited: 100, Proof: ProvenLoss}, {ID: "b", NVisited: 50}, {ID: "c", NVisited: 10, Proof: ProvenWin}}
+	assert.Equal(t, "c", BestAction(stats))
+	assert.Equal(t, "b", BestAction(stats[:2]))
+	assert.Equal(t, "a", BestAction(stats[:1]))
+}
@@ -0,0 +1,86 @@
+package tree
+
+import (
+	"math"
+	"math/rand"
+)
+
+// maxSampleAttempts is how many times SampleAction is retried when it
+// keeps returning actions the node already has
+const maxSampleAttempts = 10
+
+type SampleRequest struct {
+	// Rand source of randomness of the tree, see StateTree.Seed
+	Rand *rand.Rand
+}
+
+// ActionSampler can be implemented by states with too many (or continuous)
+// actions to list, progressive widening then draws new actions from it
+// instead of PossibleActions
+type ActionSampler interface {
+	SampleAction(SampleRequest) string
+}
+
+func (config StateTreeConfig) widening() bool {
+	return config.WideningK > 0
+}
+
+func (n *Node) nVisited() int {
+	total := 0
+	for _, action := range n.Actions {
+		total += action.NVisited
+	}
+	return total
+}
+
+// widen add actions to the node until it holds ceil(k * n^alpha) of them
+// or no new action can be found
+func (st *StateTree) widen(node *Node, state State, config StateTreeConfig) {
+	limit := int(math.Ceil(config.WideningK * math.Pow(float64(node.nVisited()), config.WideningAlpha)))
+	if limit < 1 {
+		limit = 1
+	}
+	for len(node.Actions) < limit {
+		actionID, ok := st.nextAction(node, state)
+		if !ok {
+			return
+		}
+		node.Actions = append(node.Actions, &Action{ID: actionID, Prior: node.prior(state, actionID)})
+	}
+}
+
+func (st *StateTree) nextAction(node *Node, state State) (string, bool) {
+	known := make(map[string]bool, len(node.Actions))
+	for _, action := range node.Actions {
+		known[action.ID] = true
+	}
+
+	if sampler, ok := state.(ActionSampler); ok {
+		for i := 0; i < maxSampleAttempts; i++ {
+			actionID := sampler.SampleAction(SampleRequest{Rand: st.rand})
+			if !known[actionID] {
+				return actionID, true
+			}
+		}
+		return "", false
+	}
+
+	// without a sampler the action with the highest prior comes first
+	next, found := "", false
+	for _, actionID := range state.PossibleActions() {
+		if known[actionID] {
+			continue
+		}
+		if !found || node.prior(state, actionID) > node.prior(state, next) {
+			next, found = actionID, true
+		}
+	}
+	return next, found
+}
+
+func isFullyWidened(node *Node, state State) bool {
+	if _, ok := state.(ActionSampler); ok {
+		return false
+	}
+	return len(node.Actions) >= len(state.PossibleActions())
+}